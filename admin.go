@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CreateAPIKeyRequest — тело запроса на выпуск ключа
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// TTL в формате Go duration, например "720h"; пусто — бессрочный ключ
+	TTL string `json:"ttl"`
+}
+
+// CreateAPIKeyResponse — выпущенный ключ; Key показывается только один раз
+type CreateAPIKeyResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"apiKey"`
+}
+
+// AdminKeys godoc
+// @Summary Управление API-ключами
+// @Description GET — список ключей, POST — выпуск нового ключа, DELETE ?id= — отзыв ключа. Требуется scope admin.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id query string false "ID ключа для DELETE"
+// @Param request body CreateAPIKeyRequest false "Параметры нового ключа для POST"
+// @Success 200 {array} APIKey "Список ключей"
+// @Success 201 {object} CreateAPIKeyResponse "Выпущенный ключ"
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 401 {object} ErrorResponse "Нет или неверный API-ключ"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Ключ не найден"
+// @Router /api/admin/keys [get]
+// @Router /api/admin/keys [post]
+// @Router /api/admin/keys [delete]
+func handleAdminKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		keys, err := apiKeys.list()
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendJSON(w, keys, http.StatusOK)
+
+	case http.MethodPost:
+		var req CreateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var ttl time.Duration
+		if req.TTL != "" {
+			var err error
+			if ttl, err = time.ParseDuration(req.TTL); err != nil {
+				sendError(w, "Invalid ttl: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		plain, key, err := apiKeys.create(req.Name, req.Scopes, ttl)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendJSON(w, CreateAPIKeyResponse{Key: plain, APIKey: key}, http.StatusCreated)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			sendError(w, `Missing required parameter: "id"`, http.StatusBadRequest)
+			return
+		}
+
+		found, err := apiKeys.revoke(id)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			sendError(w, fmt.Sprintf("API key '%s' not found", id), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// runAPIKeyCommand — CLI для управления ключами без запущенного сервера:
+//
+//	DOUPIG apikey create -name partner -scopes draws:read,tickets:check -ttl 720h
+//	DOUPIG apikey list
+//	DOUPIG apikey revoke <id>
+func runAPIKeyCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: apikey create|list|revoke [flags]")
+	}
+
+	fs := flag.NewFlagSet("apikey "+args[0], flag.ExitOnError)
+	configPath := fs.String("config", "", "путь к JSON-файлу конфигурации")
+	name := fs.String("name", "", "описание ключа")
+	scopes := fs.String("scopes", scopeDrawsRead, "области доступа через запятую: "+strings.Join(knownScopes, ", "))
+	ttl := fs.Duration("ttl", 0, "срок действия ключа, 0 — бессрочный")
+	fs.Parse(args[1:])
+
+	c, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	store, err := newAPIKeyStore(c.Auth.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "create":
+		plain, key, err := store.create(*name, strings.Split(*scopes, ","), *ttl)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("id:  %s\nkey: %s\n", key.ID, plain)
+		fmt.Println("Сохраните ключ: повторно его показать невозможно")
+
+	case "list":
+		keys, err := store.list()
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tSCOPES\tCREATED\tEXPIRES")
+		for _, k := range keys {
+			expires := "never"
+			if k.ExpiresAt != nil {
+				expires = k.ExpiresAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, strings.Join(k.Scopes, ","), k.CreatedAt.Format(time.RFC3339), expires)
+		}
+		tw.Flush()
+
+	case "revoke":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: apikey revoke <id>")
+		}
+		found, err := store.revoke(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("API key '%s' not found", fs.Arg(0))
+		}
+		fmt.Println("revoked", fs.Arg(0))
+
+	default:
+		return fmt.Errorf("unknown apikey command %q", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Публичный API версионируется по префиксу пути: /api/v1/..., /api/v2/...
+// Схема каждой версии строится из моделей апстрима (models.go) и не меняется
+// вместе с ним; сырые ответы Stoloto доступны отдельно под /api/raw/.
+//
+// Новая версия добавляется записью в apiVersions со своими типами и маршрутами.
+// Старая версия при этом помечается Deprecated и получает дату Sunset —
+// клиенты увидят это в заголовках каждого ответа.
+
+// apiVersion — версия публичного API
+type apiVersion struct {
+	Name       string
+	Deprecated bool
+	// Sunset — дата, после которой версия может быть удалена
+	Sunset time.Time
+	Routes []apiRoute
+}
+
+// apiRoute — маршрут версии; Pattern задаётся относительно /api/<версия>
+type apiRoute struct {
+	Method  string
+	Pattern string
+	Handler http.HandlerFunc
+}
+
+var apiVersions = []apiVersion{
+	{Name: "v1", Routes: v1Routes},
+}
+
+// currentAPIVersion доступна и без префикса версии: /api/games — это /api/v1/games
+const currentAPIVersion = "v1"
+
+// registerAPIVersions регистрирует маршруты всех версий в mux
+func registerAPIVersions(mux *http.ServeMux) {
+	for _, v := range apiVersions {
+		for _, route := range v.Routes {
+			mux.Handle(route.Method+" /api/"+v.Name+route.Pattern, v.wrap(route.Handler))
+			if v.Name == currentAPIVersion {
+				mux.Handle(route.Method+" /api"+route.Pattern, v.wrap(route.Handler))
+			}
+		}
+	}
+}
+
+// wrap выставляет заголовки версии до вызова обработчика
+func (v apiVersion) wrap(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", v.Name)
+		if v.Deprecated {
+			w.Header().Set("Deprecation", "true")
+		}
+		if !v.Sunset.IsZero() {
+			w.Header().Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+		}
+		next(w, r)
+	})
+}
+
+// serveAPI отдаёт v в JSON с ETag и заголовками кеширования. upstream —
+// заголовки ответа апстрима, из которого собраны данные: по ним ответ
+// помечается как устаревший.
+func serveAPI(w http.ResponseWriter, r *http.Request, v any, hints cacheHints, upstream http.Header) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		sendError(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	serveBody(w, r, append(body, '\n'), hints, upstream)
+}
+
+// sendAPIError переводит ошибку получения данных в статус ответа
+func sendAPIError(w http.ResponseWriter, err error) {
+	var statusErr *upstreamStatusError
+	switch {
+	case errors.Is(err, errDrawNotFound), errors.Is(err, errGameNotFound), errors.Is(err, errCardNotFound):
+		sendError(w, err.Error(), http.StatusNotFound)
+	case errors.As(err, &statusErr):
+		log.Printf("Upstream returned %s", statusErr.Status)
+		sendError(w, "Upstream error: "+statusErr.Status, upstreamStatus(statusErr.StatusCode))
+	default:
+		sendError(w, err.Error(), upstreamErrorStatus(err))
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Схема API v1. Поля только добавляются; переименование или смена типа —
+// это уже v2.
+
+// GameV1 — лотерея
+type GameV1 struct {
+	Name      string `json:"name" example:"6x45"`
+	Title     string `json:"title" example:"Гослото «6 из 45»"`
+	Type      string `json:"type" enums:"draw,bingo,instant" example:"draw"`
+	SalesOpen bool   `json:"salesOpen"`
+	// CurrentDraw — тираж, на который идут продажи
+	CurrentDraw *DrawV1 `json:"currentDraw,omitempty"`
+	// LastDraw — последний проведённый тираж
+	LastDraw *DrawV1 `json:"lastDraw,omitempty"`
+}
+
+// GamesV1 — список лотерей
+type GamesV1 struct {
+	Games []GameV1 `json:"games"`
+	// Total — сколько лотерей подходит под фильтры, без учёта страницы
+	Total int `json:"total"`
+	// NextCursor — значение cursor для следующей страницы; пусто на последней
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// DrawV1 — тираж. Денежные суммы в рублях.
+type DrawV1 struct {
+	Game         string            `json:"game" example:"6x45"`
+	Number       int               `json:"number" example:"1234"`
+	Date         time.Time         `json:"date"`
+	Status       string            `json:"status" enums:"open,completed" example:"completed"`
+	SuperPrize   int64             `json:"superPrize" example:"50000000"`
+	Numbers      []int             `json:"numbers,omitempty"`
+	Prizes       []PrizeCategoryV1 `json:"prizes,omitempty"`
+	TotalWinners int               `json:"totalWinners"`
+	TotalPrize   int64             `json:"totalPrize"`
+}
+
+// PrizeCategoryV1 — выигрышная категория тиража
+type PrizeCategoryV1 struct {
+	Category int `json:"category" example:"1"`
+	// Matched — сколько чисел нужно угадать, например "6" или "5+1"
+	Matched string `json:"matched" example:"6"`
+	Winners int    `json:"winners"`
+	Amount  int64  `json:"amount"`
+}
+
+// MomentCardV1 — моментальная лотерея
+type MomentCardV1 struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Price     int64  `json:"price"`
+	MaxPrize  int64  `json:"maxPrize"`
+	Available bool   `json:"available"`
+	Badge     string `json:"badge,omitempty"`
+	// ImageURL — большая картинка; все размеры в Images
+	ImageURL string             `json:"imageUrl,omitempty"`
+	Images   MomentCardImagesV1 `json:"images"`
+}
+
+// MomentCardImagesV1 — картинки карточки
+type MomentCardImagesV1 struct {
+	Small string `json:"small,omitempty"`
+	Large string `json:"large,omitempty"`
+}
+
+// MomentCardsV1 — список моментальных лотерей
+type MomentCardsV1 struct {
+	Cards []MomentCardV1 `json:"cards"`
+	// Total — сколько карточек подходит под фильтры; у /momental не заполняется
+	Total int `json:"total,omitempty"`
+}
+
+var v1Routes = []apiRoute{
+	{http.MethodGet, "/games", handleV1Games},
+	{http.MethodGet, "/games/{name}", handleV1Game},
+	{http.MethodGet, "/games/{name}/draws/latest", handleV1LatestDraw},
+	{http.MethodGet, "/games/{name}/draws/{number}", handleV1Draw},
+	{http.MethodGet, "/momental", handleV1Momental},
+	{http.MethodGet, "/momental/cards", handleV1MomentCards},
+	{http.MethodGet, "/momental/cards/{id}", handleV1MomentCard},
+	{http.MethodGet, "/momental/changes", handleV1MomentalChanges},
+}
+
+func toGameV1(g *upstreamGame) GameV1 {
+	game := GameV1{Name: g.Name, Title: g.Title, Type: g.Type, SalesOpen: g.SalesOpen}
+	if g.Draw != nil {
+		d := toDrawV1(g.Name, g.Draw)
+		game.CurrentDraw = &d
+	}
+	if g.CompletedDraw != nil {
+		d := toDrawV1(g.Name, g.CompletedDraw)
+		game.LastDraw = &d
+	}
+	return game
+}
+
+func toDrawV1(game string, d *upstreamDraw) DrawV1 {
+	draw := DrawV1{
+		Game:         game,
+		Number:       d.Number,
+		Date:         d.Date.Time,
+		Status:       "open",
+		SuperPrize:   rubles(d.SuperPrize),
+		TotalWinners: d.TotalWinners,
+		TotalPrize:   rubles(d.TotalPrize),
+	}
+	if d.completed() {
+		draw.Status = "completed"
+	}
+	if d.Combination != nil {
+		for _, s := range d.Combination.Serialized {
+			if n, err := strconv.Atoi(s); err == nil {
+				draw.Numbers = append(draw.Numbers, n)
+			}
+		}
+	}
+	for _, c := range d.WinningCategories {
+		draw.Prizes = append(draw.Prizes, PrizeCategoryV1{
+			Category: c.Number,
+			Matched:  c.Combination,
+			Winners:  c.Participants,
+			Amount:   rubles(c.Amount),
+		})
+	}
+	return draw
+}
+
+func toMomentCardV1(c *upstreamCard) MomentCardV1 {
+	return MomentCardV1{
+		ID:        c.ID,
+		Name:      c.Name,
+		Price:     rubles(c.Price),
+		MaxPrize:  rubles(c.MaxPrize),
+		Available: c.Available,
+		Badge:     c.Badge,
+		ImageURL:  c.Images.Large,
+		Images:    MomentCardImagesV1{Small: c.Images.Small, Large: c.Images.Large},
+	}
+}
+
+func rubles(v float64) int64 {
+	return int64(math.Round(v))
+}
+
+// V1Games godoc
+// @Summary Список лотерей
+// @Description Лотереи с текущим и последним проведённым тиражом. Фильтры объединяются через «и»; без limit возвращается весь список.
+// @Tags v1
+// @Produce json
+// @Security ApiKeyAuth
+// @Param type query []string false "Тип лотереи" Enums(draw, bingo, instant) collectionFormat(csv)
+// @Param active query bool false "Есть текущий тираж"
+// @Param salesOpen query bool false "Продажи открыты"
+// @Param name query string false "Подстрока или шаблон (*, ?) имени или названия, без учёта регистра"
+// @Param sort query string false "Сортировка; минус — по убыванию" Enums(name, -name, superPrize, -superPrize, nextDraw, -nextDraw)
+// @Param limit query int false "Размер страницы (до 100)"
+// @Param cursor query string false "nextCursor предыдущей страницы (только для того же sort)"
+// @Success 200 {object} GamesV1
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Failure 504 {object} ErrorResponse "Апстрим не ответил вовремя"
+// @Router /api/v1/games [get]
+// @Router /api/games [get]
+func handleV1Games(w http.ResponseWriter, r *http.Request) {
+	query, err := parseGamesQuery(r.URL.Query())
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	games, header, err := fetchGames(r.Context())
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+
+	all := make([]GameV1, 0, len(games.Games))
+	for i := range games.Games {
+		all = append(all, toGameV1(&games.Games[i]))
+	}
+	page, total, next := query.apply(all)
+
+	out := GamesV1{Games: page, Total: total, NextCursor: next}
+	if out.Games == nil {
+		out.Games = []GameV1{}
+	}
+	serveAPI(w, r, out, gamesHints(games, time.Now()), header)
+}
+
+// V1Game godoc
+// @Summary Лотерея
+// @Description Лотерея с текущим и последним проведённым тиражом
+// @Tags v1
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Название игры (например: 5x36plus, 6x45)"
+// @Success 200 {object} GameV1
+// @Failure 404 {object} ErrorResponse "Игра не найдена"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/v1/games/{name} [get]
+// @Router /api/games/{name} [get]
+func handleV1Game(w http.ResponseWriter, r *http.Request) {
+	game, games, header, err := findGame(r.Context(), r.PathValue("name"))
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+	serveAPI(w, r, toGameV1(game), gamesHints(games, time.Now()), header)
+}
+
+// V1Draw godoc
+// @Summary Тираж
+// @Description Тираж по названию игры и номеру
+// @Tags v1
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Название игры (например: 5x36plus, 6x45)"
+// @Param number path int true "Номер тиража"
+// @Success 200 {object} DrawV1
+// @Failure 400 {object} ErrorResponse "Неверный номер тиража"
+// @Failure 404 {object} ErrorResponse "Тираж не найден"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/v1/games/{name}/draws/{number} [get]
+// @Router /api/games/{name}/draws/{number} [get]
+func handleV1Draw(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil || number <= 0 {
+		sendError(w, "Invalid draw number: "+r.PathValue("number"), http.StatusBadRequest)
+		return
+	}
+
+	draw, header, err := fetchDraw(r.Context(), name, number)
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+	serveAPI(w, r, toDrawV1(name, draw), drawHints(draw, time.Now()), header)
+}
+
+// V1LatestDraw godoc
+// @Summary Последний проведённый тираж
+// @Description Последний тираж игры, по которому известны результаты
+// @Tags v1
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Название игры (например: 5x36plus, 6x45)"
+// @Success 200 {object} DrawV1
+// @Failure 404 {object} ErrorResponse "Игра не найдена или ещё не проводилась"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/v1/games/{name}/draws/latest [get]
+// @Router /api/games/{name}/draws/latest [get]
+func handleV1LatestDraw(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	draw, header, err := fetchLatestDraw(r.Context(), name)
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+	serveAPI(w, r, toDrawV1(name, draw), drawHints(draw, time.Now()), header)
+}
+
+// V1Momental godoc
+// @Summary Моментальные лотереи
+// @Tags v1
+// @Produce json
+// @Security ApiKeyAuth
+// @Param platform query string false "Платформа витрины CMS" default(OS)
+// @Param userSegment query string false "Сегмент пользователей витрины CMS" default(ALL)
+// @Success 200 {object} MomentCardsV1
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/v1/momental [get]
+// @Router /api/momental [get]
+func handleV1Momental(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseMomentalOptions(r.URL.Query())
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cards, header, err := fetchMomentalCards(r.Context(), opts)
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+
+	out := MomentCardsV1{Cards: make([]MomentCardV1, 0, len(cards))}
+	for i := range cards {
+		out.Cards = append(out.Cards, toMomentCardV1(&cards[i]))
+	}
+	serveAPI(w, r, out, momentalCacheHints(nil, time.Now()), header)
+}
+
+// V1MomentCards godoc
+// @Summary Поиск моментальных лотерей
+// @Description Карточки моментальных лотерей с поиском по названию, фильтрами по цене билета и максимальному выигрышу и сортировкой. Суммы в рублях, границы включаются.
+// @Tags v1
+// @Produce json
+// @Security ApiKeyAuth
+// @Param q query string false "Подстрока названия, без учёта регистра"
+// @Param available query bool false "Доступна для покупки"
+// @Param priceFrom query int false "Цена билета от"
+// @Param priceTo query int false "Цена билета до"
+// @Param prizeFrom query int false "Максимальный выигрыш от"
+// @Param prizeTo query int false "Максимальный выигрыш до"
+// @Param sort query string false "Сортировка; минус — по убыванию" Enums(name, -name, price, -price, maxPrize, -maxPrize)
+// @Param platform query string false "Платформа витрины CMS" default(OS)
+// @Param userSegment query string false "Сегмент пользователей витрины CMS" default(ALL)
+// @Success 200 {object} MomentCardsV1
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/v1/momental/cards [get]
+// @Router /api/momental/cards [get]
+func handleV1MomentCards(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseMomentalOptions(r.URL.Query())
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query, err := parseCardsQuery(r.URL.Query())
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cards, header, err := fetchMomentalCards(r.Context(), opts)
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+
+	all := make([]MomentCardV1, 0, len(cards))
+	for i := range cards {
+		all = append(all, toMomentCardV1(&cards[i]))
+	}
+	found := query.apply(all)
+	serveAPI(w, r, MomentCardsV1{Cards: found, Total: len(found)}, momentalCacheHints(nil, time.Now()), header)
+}
+
+// V1MomentCard godoc
+// @Summary Моментальная лотерея
+// @Tags v1
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Идентификатор карточки"
+// @Param platform query string false "Платформа витрины CMS" default(OS)
+// @Param userSegment query string false "Сегмент пользователей витрины CMS" default(ALL)
+// @Success 200 {object} MomentCardV1
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 404 {object} ErrorResponse "Карточка не найдена"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/v1/momental/cards/{id} [get]
+// @Router /api/momental/cards/{id} [get]
+func handleV1MomentCard(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseMomentalOptions(r.URL.Query())
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cards, header, err := fetchMomentalCards(r.Context(), opts)
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+
+	id := r.PathValue("id")
+	for i := range cards {
+		if cards[i].ID == id {
+			serveAPI(w, r, toMomentCardV1(&cards[i]), momentalCacheHints(nil, time.Now()), header)
+			return
+		}
+	}
+	sendAPIError(w, fmt.Errorf("%w: '%s'", errCardNotFound, id))
+}
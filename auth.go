@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Области доступа API-ключей
+const (
+	scopeDrawsRead    = "draws:read"
+	scopeTicketsCheck = "tickets:check"
+	scopeAdmin        = "admin"
+)
+
+var knownScopes = []string{scopeDrawsRead, scopeTicketsCheck, scopeAdmin}
+
+// AuthConfig — настройки аутентификации потребителей /api/*.
+// Routes сопоставляет префикс пути с областью, которая нужна для доступа;
+// пути, не попавшие ни под один префикс (например, /swagger/), открыты.
+type AuthConfig struct {
+	Enabled  bool              `json:"enabled"`
+	KeysFile string            `json:"keysFile"`
+	Routes   map[string]string `json:"routes"`
+}
+
+// APIKey — запись о ключе. Сам ключ не хранится, только его SHA-256.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Hash      string     `json:"hash,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func (k APIKey) expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// allows возвращает true, если у ключа есть scope; admin разрешает всё
+func (k APIKey) allows(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, scopeAdmin)
+}
+
+// apiKeyStore — ключи в локальном JSON-файле. Файл перечитывается при изменении,
+// поэтому ключи, созданные через CLI, подхватываются работающим сервером.
+type apiKeyStore struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	keys    []APIKey
+}
+
+var apiKeys *apiKeyStore
+
+func newAPIKeyStore(path string) (*apiKeyStore, error) {
+	s := &apiKeyStore{path: path}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// reload перечитывает файл, если он изменился с прошлой загрузки. Вызывается под mu.
+func (s *apiKeyStore) reload() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.keys, s.modTime = nil, time.Time{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading API keys: %w", err)
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("error reading API keys: %w", err)
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("error parsing API keys: %w", err)
+	}
+
+	s.keys, s.modTime = keys, info.ModTime()
+	return nil
+}
+
+func (s *apiKeyStore) save() error {
+	data, err := json.MarshalIndent(s.keys, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы сервер не прочитал файл наполовину
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".apikeys-*")
+	if err != nil {
+		return fmt.Errorf("error saving API keys: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error saving API keys: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error saving API keys: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("error saving API keys: %w", err)
+	}
+
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// lookup ищет ключ по его открытому значению
+func (s *apiKeyStore) lookup(key string) (APIKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return APIKey{}, false, err
+	}
+
+	hash := hashAPIKey(key)
+	for _, k := range s.keys {
+		if k.Hash == hash {
+			return k, true, nil
+		}
+	}
+	return APIKey{}, false, nil
+}
+
+func (s *apiKeyStore) list() ([]APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, len(s.keys))
+	for i, k := range s.keys {
+		k.Hash = ""
+		keys[i] = k
+	}
+	return keys, nil
+}
+
+// create выпускает новый ключ и возвращает его открытое значение —
+// это единственный момент, когда его можно увидеть
+func (s *apiKeyStore) create(name string, scopes []string, ttl time.Duration) (string, APIKey, error) {
+	for _, scope := range scopes {
+		if !slices.Contains(knownScopes, scope) {
+			return "", APIKey{}, fmt.Errorf("unknown scope %q, expected one of %v", scope, knownScopes)
+		}
+	}
+	if len(scopes) == 0 {
+		return "", APIKey{}, fmt.Errorf("at least one scope is required")
+	}
+
+	secret := make([]byte, 32)
+	id := make([]byte, 8)
+	if _, err := rand.Read(secret); err != nil {
+		return "", APIKey{}, err
+	}
+	if _, err := rand.Read(id); err != nil {
+		return "", APIKey{}, err
+	}
+
+	plain := "stl_" + base64.RawURLEncoding.EncodeToString(secret)
+	key := APIKey{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Hash:      hashAPIKey(plain),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		expires := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expires
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return "", APIKey{}, err
+	}
+	s.keys = append(s.keys, key)
+	if err := s.save(); err != nil {
+		return "", APIKey{}, err
+	}
+
+	key.Hash = ""
+	return plain, key, nil
+}
+
+func (s *apiKeyStore) revoke(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return false, err
+	}
+
+	n := len(s.keys)
+	s.keys = slices.DeleteFunc(s.keys, func(k APIKey) bool { return k.ID == id })
+	if len(s.keys) == n {
+		return false, nil
+	}
+	return true, s.save()
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type apiKeyContextKey struct{}
+
+// authenticatedKey возвращает ключ, которым аутентифицирован запрос
+func authenticatedKey(r *http.Request) (APIKey, bool) {
+	k, ok := r.Context().Value(apiKeyContextKey{}).(APIKey)
+	return k, ok
+}
+
+var (
+	errAPIKeyMissing   = errors.New("API key required")
+	errAPIKeyInvalid   = errors.New("invalid API key")
+	errAPIKeyExpired   = errors.New("API key expired")
+	errAPIKeyForbidden = errors.New("API key has no required scope")
+)
+
+// verifyAPIKey проверяет ключ и его область. errAPIKeyMissing, errAPIKeyInvalid
+// и errAPIKeyExpired означают «не аутентифицирован», errAPIKeyForbidden —
+// «нет прав»; остальные ошибки — сбой хранилища ключей.
+func verifyAPIKey(plain, scope string) (APIKey, error) {
+	if plain == "" {
+		return APIKey{}, errAPIKeyMissing
+	}
+
+	key, found, err := apiKeys.lookup(plain)
+	if err != nil {
+		return APIKey{}, err
+	}
+	if !found {
+		return APIKey{}, errAPIKeyInvalid
+	}
+	if key.expired(time.Now()) {
+		return APIKey{}, errAPIKeyExpired
+	}
+	if !key.allows(scope) {
+		return APIKey{}, fmt.Errorf("%w %q", errAPIKeyForbidden, scope)
+	}
+	return key, nil
+}
+
+// withAuth проверяет API-ключ для путей из cfg.Auth.Routes
+func withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.Auth.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scope, ok := longestPrefix(cfg.Auth.Routes, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := verifyAPIKey(clientAPIKey(r), scope)
+		switch {
+		case errors.Is(err, errAPIKeyForbidden):
+			sendError(w, err.Error(), http.StatusForbidden)
+			return
+		case errors.Is(err, errAPIKeyMissing), errors.Is(err, errAPIKeyInvalid), errors.Is(err, errAPIKeyExpired):
+			if !rateLimitUnauthorized(w, r) {
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `APIKey realm="stoloto-proxy"`)
+			if errors.Is(err, errAPIKeyMissing) {
+				err = fmt.Errorf("%w: pass it in X-API-Key header or api_key query parameter", err)
+			}
+			sendError(w, err.Error(), http.StatusUnauthorized)
+			return
+		case err != nil:
+			log.Printf("API key store error: %v", err)
+			sendError(w, "Error checking API key", http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+	})
+}
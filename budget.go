@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Классы приоритета исходящих запросов к Stoloto. Меньшее значение
+// обслуживается раньше: пока в очереди есть запросы пользователей,
+// фоновые задачи (обновление кеша, обход архива) ждут.
+type priority int
+
+const (
+	priorityInteractive priority = iota
+	priorityBackground
+	priorityBulk
+	numPriorities
+)
+
+func (p priority) String() string {
+	switch p {
+	case priorityInteractive:
+		return "interactive"
+	case priorityBackground:
+		return "background"
+	default:
+		return "bulk"
+	}
+}
+
+type priorityContextKey struct{}
+
+// withPriority помечает исходящие запросы, сделанные с ctx, классом p
+func withPriority(ctx context.Context, p priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey{}, p)
+}
+
+func priorityFrom(ctx context.Context) priority {
+	if p, ok := ctx.Value(priorityContextKey{}).(priority); ok {
+		return p
+	}
+	return priorityInteractive
+}
+
+// UpstreamBudgetConfig — общий лимит запросов к Stoloto на партнёрский токен.
+// RequestsPerSecond = 0 отключает ограничение.
+type UpstreamBudgetConfig struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+	// MaxQueue — максимальная длина очереди каждого класса
+	MaxQueue int `json:"maxQueue"`
+}
+
+var errUpstreamQueueFull = errors.New("upstream request budget exhausted: queue is full")
+
+var (
+	upstreamQueueDepth = newGauge("stoloto_upstream_queue_depth",
+		"Number of outbound requests waiting for the upstream budget")
+	upstreamRequestsTotal = newCounter("stoloto_upstream_requests_total",
+		"Outbound requests admitted by the upstream budget")
+	upstreamRejectedTotal = newCounter("stoloto_upstream_rejected_total",
+		"Outbound requests rejected because the queue was full")
+	upstreamWaitSeconds = newCounter("stoloto_upstream_wait_seconds_total",
+		"Total time outbound requests spent waiting for the upstream budget")
+)
+
+// upstreamBudget создаётся в main; nil означает отсутствие ограничения
+var upstreamBudget *outboundLimiter
+
+// outboundLimiter — token bucket с очередями по приоритетам
+type outboundLimiter struct {
+	rate     float64
+	burst    float64
+	maxQueue int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	queues [numPriorities][]*budgetWaiter
+	timer  *time.Timer
+}
+
+type budgetWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func newOutboundLimiter(c UpstreamBudgetConfig) *outboundLimiter {
+	if c.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := float64(max(c.Burst, 1))
+	return &outboundLimiter{
+		rate:     c.RequestsPerSecond,
+		burst:    burst,
+		maxQueue: c.MaxQueue,
+		tokens:   burst,
+		last:     time.Now(),
+	}
+}
+
+// wait блокируется, пока запрос класса p не получит токен или не отменится ctx
+func (l *outboundLimiter) wait(ctx context.Context, p priority) error {
+	start := time.Now()
+
+	l.mu.Lock()
+	l.refill(start)
+
+	if l.tokens >= 1 && l.queued() == 0 {
+		l.tokens--
+		l.mu.Unlock()
+		upstreamRequestsTotal.add(labels("priority", p.String()), 1)
+		return nil
+	}
+
+	if l.maxQueue > 0 && len(l.queues[p]) >= l.maxQueue {
+		l.mu.Unlock()
+		upstreamRejectedTotal.add(labels("priority", p.String()), 1)
+		return errUpstreamQueueFull
+	}
+
+	w := &budgetWaiter{ready: make(chan struct{})}
+	l.queues[p] = append(l.queues[p], w)
+	l.updateDepth(p)
+	l.schedule()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		upstreamRequestsTotal.add(labels("priority", p.String()), 1)
+		upstreamWaitSeconds.add(labels("priority", p.String()), time.Since(start).Seconds())
+		return nil
+
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.abandon(p, w)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// abandon убирает отменённый запрос из очереди. Если токен выдан
+// одновременно с отменой, он достаётся следующему. Вызывается под mu.
+func (l *outboundLimiter) abandon(p priority, w *budgetWaiter) {
+	if w.granted {
+		l.tokens++
+		l.dispatch()
+		return
+	}
+	l.remove(p, w)
+}
+
+func (l *outboundLimiter) refill(now time.Time) {
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+func (l *outboundLimiter) queued() int {
+	n := 0
+	for _, q := range l.queues {
+		n += len(q)
+	}
+	return n
+}
+
+// dispatch раздаёт накопившиеся токены, начиная с самого приоритетного класса.
+// Вызывается под mu.
+func (l *outboundLimiter) dispatch() {
+	l.refill(time.Now())
+
+	for p := range l.queues {
+		for len(l.queues[p]) > 0 && l.tokens >= 1 {
+			w := l.queues[p][0]
+			l.queues[p] = l.queues[p][1:]
+			l.tokens--
+			w.granted = true
+			close(w.ready)
+		}
+		l.updateDepth(priority(p))
+	}
+
+	l.schedule()
+}
+
+// schedule заводит таймер на момент появления следующего токена. Вызывается под mu.
+func (l *outboundLimiter) schedule() {
+	if l.queued() == 0 || l.timer != nil {
+		return
+	}
+
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	l.timer = time.AfterFunc(max(delay, 0), func() {
+		l.mu.Lock()
+		l.timer = nil
+		l.dispatch()
+		l.mu.Unlock()
+	})
+}
+
+func (l *outboundLimiter) remove(p priority, w *budgetWaiter) {
+	for i, q := range l.queues[p] {
+		if q == w {
+			l.queues[p] = append(l.queues[p][:i], l.queues[p][i+1:]...)
+			break
+		}
+	}
+	l.updateDepth(p)
+}
+
+func (l *outboundLimiter) updateDepth(p priority) {
+	upstreamQueueDepth.set(labels("priority", p.String()), float64(len(l.queues[p])))
+}
+
+// budgetTransport пропускает каждую попытку запроса, включая повторы, через upstreamBudget
+type budgetTransport struct {
+	next http.RoundTripper
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if upstreamBudget != nil {
+		if err := upstreamBudget.wait(req.Context(), priorityFrom(req.Context())); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
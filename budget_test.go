@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitQueued ждёт, пока в очередях лимитера окажется n запросов
+func waitQueued(t *testing.T, l *outboundLimiter, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		l.mu.Lock()
+		queued := l.queued()
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d requests queued, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOutboundLimiterDisabled(t *testing.T) {
+	if l := newOutboundLimiter(UpstreamBudgetConfig{}); l != nil {
+		t.Fatal("limiter without requestsPerSecond is not nil")
+	}
+}
+
+func TestOutboundLimiterBurstThenRate(t *testing.T) {
+	l := newOutboundLimiter(UpstreamBudgetConfig{RequestsPerSecond: 20, Burst: 3})
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		if err := l.wait(ctx, priorityInteractive); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d > 20*time.Millisecond {
+		t.Fatalf("burst took %v, want no waiting", d)
+	}
+
+	if err := l.wait(ctx, priorityInteractive); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Fatalf("request over the burst passed after %v, want about 50ms", d)
+	}
+}
+
+func TestOutboundLimiterPriority(t *testing.T) {
+	l := newOutboundLimiter(UpstreamBudgetConfig{RequestsPerSecond: 10, Burst: 1})
+	ctx := context.Background()
+	if err := l.wait(ctx, priorityInteractive); err != nil {
+		t.Fatal(err)
+	}
+
+	// Очередь набирается от младшего класса к старшему, а токены
+	// достаются в обратном порядке
+	order := make(chan priority, numPriorities)
+	for i, p := range []priority{priorityBulk, priorityBackground, priorityInteractive} {
+		go func() {
+			if err := l.wait(ctx, p); err != nil {
+				t.Error(err)
+			}
+			order <- p
+		}()
+		waitQueued(t, l, i+1)
+	}
+
+	for _, want := range []priority{priorityInteractive, priorityBackground, priorityBulk} {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Fatalf("%s request admitted, want %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s request was never admitted", want)
+		}
+	}
+}
+
+func TestOutboundLimiterQueueFull(t *testing.T) {
+	l := newOutboundLimiter(UpstreamBudgetConfig{RequestsPerSecond: 0.01, Burst: 1, MaxQueue: 1})
+	if err := l.wait(context.Background(), priorityBulk); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.wait(ctx, priorityBulk)
+	waitQueued(t, l, 1)
+
+	if err := l.wait(ctx, priorityBulk); !errors.Is(err, errUpstreamQueueFull) {
+		t.Fatalf("second bulk request: %v, want errUpstreamQueueFull", err)
+	}
+
+	// Очереди у классов свои: фоновый запрос встаёт в очередь
+	go l.wait(ctx, priorityBackground)
+	waitQueued(t, l, 2)
+}
+
+func TestOutboundLimiterCancel(t *testing.T) {
+	l := newOutboundLimiter(UpstreamBudgetConfig{RequestsPerSecond: 0.01, Burst: 1})
+	if err := l.wait(context.Background(), priorityInteractive); err != nil {
+		t.Fatal(err)
+	}
+
+	// Отменённый запрос уходит из очереди
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- l.wait(ctx, priorityInteractive) }()
+	waitQueued(t, l, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled request: %v, want context.Canceled", err)
+	}
+	waitQueued(t, l, 0)
+}
+
+func TestOutboundLimiterAbandonGrantedRefunds(t *testing.T) {
+	l := newOutboundLimiter(UpstreamBudgetConfig{RequestsPerSecond: 0.001, Burst: 1})
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Токен выдан первому запросу, но тот уже отменён: токен переходит ко второму
+	first := &budgetWaiter{ready: make(chan struct{})}
+	second := &budgetWaiter{ready: make(chan struct{})}
+	l.queues[priorityBulk] = append(l.queues[priorityBulk], first)
+	l.tokens = 1
+	l.dispatch()
+	if !first.granted {
+		t.Fatal("first request was not granted the token")
+	}
+	l.queues[priorityBulk] = append(l.queues[priorityBulk], second)
+
+	l.abandon(priorityBulk, first)
+	if !second.granted {
+		t.Fatal("token of the cancelled request was not passed on")
+	}
+	if l.queued() != 0 || l.tokens >= 1 {
+		t.Fatalf("after refund: %d queued, %.2f tokens; want 0 and 0", l.queued(), l.tokens)
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaleConfig — отдача последнего удачного ответа, если апстрим вернул 5xx
+// или не ответил. MaxStale задаёт допустимый возраст данных по префиксу
+// пути апстрима; пути без совпадения используют Default, нулевое значение
+// отключает fallback.
+type StaleConfig struct {
+	Default  Duration            `json:"default"`
+	MaxStale map[string]Duration `json:"maxStale"`
+	// ArchiveDir — каталог, где удачные ответы сохраняются на диск
+	// и переживают перезапуск; пусто — только память
+	ArchiveDir string `json:"archiveDir"`
+	// MaxEntries и MaxBytes ограничивают число и суммарный размер тел
+	// сохранённых ответов, в памяти и в архиве; 0 — без ограничения
+	MaxEntries int   `json:"maxEntries"`
+	MaxBytes   int64 `json:"maxBytes"`
+}
+
+func (c StaleConfig) maxStaleFor(path string) time.Duration {
+	if d, ok := longestPrefix(c.MaxStale, path); ok {
+		return time.Duration(d)
+	}
+	return time.Duration(c.Default)
+}
+
+// cachedResponse — сохранённый ответ апстрима
+type cachedResponse struct {
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body"`
+	FetchedAt time.Time   `json:"fetchedAt"`
+}
+
+func (e *cachedResponse) age(now time.Time) time.Duration {
+	return now.Sub(e.FetchedAt)
+}
+
+// response собирает из записи *http.Response, как будто он пришёл из сети
+func (e *cachedResponse) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// responseCache хранит последние удачные ответы апстрима. Размер ограничен
+// MaxEntries и MaxBytes: при переполнении вытесняются давно не запрошенные
+// записи, вместе с их файлами в архиве. Записи архива, оставшиеся с прошлого
+// запуска, учитываются сразу, а читаются с диска при первом обращении.
+type responseCache struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	lru        *list.List
+	bytes      int64
+	maxEntries int
+	maxBytes   int64
+	dir        string
+}
+
+// cacheItem — элемент LRU. resp == nil, пока запись прочитана только
+// из каталога архива; size тогда — размер файла.
+type cacheItem struct {
+	id   string
+	size int64
+	resp *cachedResponse
+}
+
+var upstreamCache = newResponseCache(StaleConfig{})
+
+var cacheEvictionsTotal = newCounter("stoloto_cache_evictions_total",
+	"Last known good responses evicted because the cache is full")
+
+func newResponseCache(c StaleConfig) *responseCache {
+	rc := &responseCache{
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		maxEntries: c.MaxEntries,
+		maxBytes:   c.MaxBytes,
+		dir:        c.ArchiveDir,
+	}
+	if rc.dir != "" {
+		rc.loadArchive()
+	}
+	return rc
+}
+
+func cacheKey(req *http.Request) string {
+	return req.URL.RequestURI()
+}
+
+// cacheID — имя записи в индексе и файла в архиве
+func cacheID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadArchive заносит в индекс файлы архива, от старых к новым
+func (c *responseCache) loadArchive() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Error reading archive %s: %v", c.dir, err)
+		}
+		return
+	}
+
+	type file struct {
+		id      string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	for _, de := range entries {
+		id, ok := strings.CutSuffix(de.Name(), ".json")
+		if !ok || de.IsDir() {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{id, info.Size(), info.ModTime()})
+	}
+	slices.SortFunc(files, func(a, b file) int { return a.modTime.Compare(b.modTime) })
+
+	c.mu.Lock()
+	for _, f := range files {
+		c.items[f.id] = c.lru.PushFront(&cacheItem{id: f.id, size: f.size})
+		c.bytes += f.size
+	}
+	evicted := c.evict()
+	c.mu.Unlock()
+
+	c.removeFiles(evicted)
+}
+
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	id := cacheID(key)
+
+	c.mu.Lock()
+	el, ok := c.items[id]
+	if !ok {
+		c.mu.Unlock()
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	item := el.Value.(*cacheItem)
+	e := item.resp
+	c.mu.Unlock()
+	if e != nil {
+		return e, true
+	}
+
+	// Запись с прошлого запуска — читаем архив
+	e = &cachedResponse{}
+	data, err := os.ReadFile(c.archivePath(id))
+	if err == nil {
+		err = json.Unmarshal(data, e)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.items[id] != el {
+		// Пока читали файл, запись вытеснили
+		return nil, false
+	}
+	if item.resp != nil {
+		// или обновили свежим ответом
+		return item.resp, true
+	}
+	if err != nil {
+		log.Printf("Corrupted archive entry for %s: %v", key, err)
+		c.remove(el)
+		return nil, false
+	}
+	c.bytes += int64(len(e.Body)) - item.size
+	item.size, item.resp = int64(len(e.Body)), e
+	return e, true
+}
+
+func (c *responseCache) put(key string, e *cachedResponse) {
+	id := cacheID(key)
+	size := int64(len(e.Body))
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	if el, ok := c.items[id]; ok {
+		item := el.Value.(*cacheItem)
+		c.bytes += size - item.size
+		item.size, item.resp = size, e
+		c.lru.MoveToFront(el)
+	} else {
+		c.items[id] = c.lru.PushFront(&cacheItem{id: id, size: size, resp: e})
+		c.bytes += size
+	}
+	evicted := c.evict()
+	c.mu.Unlock()
+
+	c.removeFiles(evicted)
+	if c.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = os.MkdirAll(c.dir, 0o755)
+	}
+	if err == nil {
+		tmp := c.archivePath(id) + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o644); err == nil {
+			err = os.Rename(tmp, c.archivePath(id))
+		}
+	}
+	if err != nil {
+		log.Printf("Error archiving response for %s: %v", key, err)
+	}
+}
+
+// evict вытесняет самые давние записи, пока кеш не уложится в лимиты,
+// и возвращает их id; вызывается под c.mu
+func (c *responseCache) evict() []string {
+	var evicted []string
+	for c.lru.Len() > 0 && ((c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes)) {
+		el := c.lru.Back()
+		evicted = append(evicted, el.Value.(*cacheItem).id)
+		c.remove(el)
+	}
+	if len(evicted) > 0 {
+		cacheEvictionsTotal.add("", float64(len(evicted)))
+	}
+	return evicted
+}
+
+// remove убирает запись из индекса; вызывается под c.mu
+func (c *responseCache) remove(el *list.Element) {
+	item := c.lru.Remove(el).(*cacheItem)
+	delete(c.items, item.id)
+	c.bytes -= item.size
+}
+
+// removeFiles удаляет из архива файлы вытесненных записей
+func (c *responseCache) removeFiles(ids []string) {
+	if c.dir == "" {
+		return
+	}
+	for _, id := range ids {
+		if err := os.Remove(c.archivePath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Error removing archived response: %v", err)
+		}
+	}
+}
+
+func (c *responseCache) archivePath(id string) string {
+	return filepath.Join(c.dir, id+".json")
+}
+
+var staleResponsesTotal = newCounter("stoloto_stale_responses_total",
+	"Responses served from the last known good copy because the upstream failed")
+
+// fetchUpstream отдаёт ответ из кеша, если для пути включён stale-while-revalidate,
+// иначе выполняет запрос с повторами. При 5xx или сетевой ошибке возвращается
+// последний удачный ответ, если он не старше допустимого для этого пути.
+func fetchUpstream(req *http.Request, policyPath string) (*http.Response, error) {
+	if resp, ok := cachedForSWR(req, policyPath); ok {
+		return resp, nil
+	}
+
+	resp, err := fetchAndStore(req, policyPath)
+	if err == nil && resp.StatusCode < 500 {
+		return resp, nil
+	}
+
+	key := cacheKey(req)
+	maxStale := cfg.Stale.maxStaleFor(policyPath)
+	e, ok := upstreamCache.get(key)
+	if !ok || maxStale <= 0 || e.age(time.Now()) > maxStale {
+		return resp, err
+	}
+
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	} else {
+		reason = resp.Status
+		resp.Body.Close()
+	}
+	log.Printf("Upstream %s failed (%s), serving data fetched %v ago", key, reason, e.age(time.Now()).Round(time.Second))
+	staleResponsesTotal.add(labels("endpoint", policyPath), 1)
+
+	stale := e.response(req)
+	markStale(stale.Header, e.age(time.Now()))
+	return stale, nil
+}
+
+// fetchAndStore выполняет запрос с повторами и запоминает удачный ответ:
+// 2xx с документом, который сам не сообщает об ошибке
+func fetchAndStore(req *http.Request, policyPath string) (*http.Response, error) {
+	resp, err := doWithRetry(req.Context(), upstreamClient, req, cfg.Retry.policyFor(policyPath))
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	e := &cachedResponse{Status: resp.StatusCode, Header: resp.Header.Clone(), Body: body, FetchedAt: time.Now()}
+	if isSuccessDocument(body) {
+		upstreamCache.put(cacheKey(req), e)
+	}
+	return e.response(req), nil
+}
+
+// isSuccessDocument проверяет, что тело — JSON-объект без признака ошибки.
+// Stoloto отвечает на ошибки кодом 200 с requestStatus != "success", CMS —
+// со status != "success"; такой ответ нельзя отдавать как последний удачный.
+func isSuccessDocument(body []byte) bool {
+	var doc struct {
+		RequestStatus *string `json:"requestStatus"`
+		Status        *string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return false
+	}
+	for _, status := range []*string{doc.RequestStatus, doc.Status} {
+		if status != nil && *status != "success" {
+			return false
+		}
+	}
+	return true
+}
+
+// markStale выставляет заголовки, по которым клиент понимает, что данные устарели
+func markStale(h http.Header, age time.Duration) {
+	h.Set("Warning", `110 - "Response is Stale"`)
+	h.Set("X-Data-Stale", "true")
+	h.Set("Age", strconv.Itoa(int(age.Seconds())))
+}
+
+// inheritStale переносит отметку об устаревших данных с промежуточного ответа,
+// например списка игр, из которого вычислялся номер последнего тиража.
+// Если устарели оба, остаётся больший возраст.
+func inheritStale(resp, from *http.Response) {
+	if from.Header.Get("X-Data-Stale") == "" {
+		return
+	}
+
+	fromAge, _ := strconv.Atoi(from.Header.Get("Age"))
+	age, _ := strconv.Atoi(resp.Header.Get("Age"))
+	markStale(resp.Header, time.Duration(max(age, fromAge))*time.Second)
+}
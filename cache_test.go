@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"DOUPIG/fakestoloto"
+)
+
+func TestStaleFallback(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+
+	var games GamesV1
+	if resp := getJSON(t, proxy.URL+"/api/v1/games", &games); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	fake.InjectFault(fakestoloto.Fault{Status: http.StatusServiceUnavailable})
+
+	var stale GamesV1
+	resp := getJSON(t, proxy.URL+"/api/v1/games", &stale)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status with failing upstream = %d, want 200 from the last known good copy", resp.StatusCode)
+	}
+	if resp.Header.Get("X-Data-Stale") != "true" || resp.Header.Get("Warning") == "" {
+		t.Fatalf("stale response headers = %v, want X-Data-Stale and Warning", resp.Header)
+	}
+	if len(stale.Games) != len(games.Games) {
+		t.Fatalf("stale games = %d, want %d", len(stale.Games), len(games.Games))
+	}
+}
+
+func TestStaleFallbackRespectsMaxStale(t *testing.T) {
+	fake, proxy := startProxy(t, func(c *Config) {
+		c.Stale.MaxStale = map[string]Duration{"/service/games/": Duration(time.Nanosecond)}
+	})
+
+	getJSON(t, proxy.URL+"/api/v1/games", nil)
+	fake.InjectFault(fakestoloto.Fault{Status: http.StatusServiceUnavailable})
+
+	if resp := getJSON(t, proxy.URL+"/api/v1/games", nil); resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502 once the copy is older than maxStale", resp.StatusCode)
+	}
+}
+
+func TestErrorDocumentsAreNotStored(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+
+	// Stoloto отвечает на несуществующий тираж кодом 200 с requestStatus "error"
+	getJSON(t, proxy.URL+"/api/v1/games/6x45/draws/999999", nil)
+	if countRequests(fake, "/service/draws/6x45/999999") == 0 {
+		t.Fatal("draw request did not reach the upstream")
+	}
+	if _, ok := upstreamCache.get(fakestoloto.APIPrefix + "/service/draws/6x45/999999"); ok {
+		t.Fatal(`document with requestStatus "error" was cached`)
+	}
+
+	// 200 с битым JSON, затем 5xx: отдать нечего
+	fake.InjectFault(fakestoloto.Fault{Malformed: true, Times: 1})
+	getJSON(t, proxy.URL+"/api/v1/games", nil)
+	fake.InjectFault(fakestoloto.Fault{Status: http.StatusServiceUnavailable})
+
+	if resp := getJSON(t, proxy.URL+"/api/v1/games", nil); resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502: malformed body must not become the last known good copy", resp.StatusCode)
+	}
+}
+
+func TestIsSuccessDocument(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{`{"requestStatus":"success","games":[]}`, true},
+		{`{"status":"success","data":{}}`, true},
+		{`{"games":[]}`, true},
+		{`{"requestStatus":"error","errors":[{"code":"DRAW_NOT_FOUND"}]}`, false},
+		{`{"status":"error"}`, false},
+		{`{"requestStatus":"success","games":[{"name":"6x4`, false},
+		{`[]`, false},
+	}
+	for _, tt := range tests {
+		if got := isSuccessDocument([]byte(tt.body)); got != tt.want {
+			t.Errorf("isSuccessDocument(%s) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	c := newResponseCache(StaleConfig{ArchiveDir: dir, MaxEntries: 2, MaxBytes: 10})
+	entry := func(body string) *cachedResponse {
+		return &cachedResponse{Status: http.StatusOK, Body: []byte(body), FetchedAt: time.Now()}
+	}
+
+	c.put("/a", entry("aaaa"))
+	c.put("/b", entry("bbbb"))
+	c.get("/a") // /a теперь новее /b
+	c.put("/c", entry("cccc"))
+
+	if _, ok := c.get("/b"); ok {
+		t.Fatal("least recently used entry /b was not evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, cacheID("/b")+".json")); !os.IsNotExist(err) {
+		t.Fatalf("archive file of evicted entry: %v, want it removed", err)
+	}
+
+	// Превышение по размеру
+	c.put("/d", entry("dddddddd"))
+	if _, ok := c.get("/a"); ok {
+		t.Fatal("entry /a was not evicted when the cache exceeded maxBytes")
+	}
+	// Тело больше maxBytes не сохраняется вовсе
+	c.put("/huge", entry("0123456789abcdef"))
+	if _, ok := c.get("/huge"); ok {
+		t.Fatal("entry larger than maxBytes was stored")
+	}
+}
+
+func TestResponseCacheArchiveReload(t *testing.T) {
+	dir := t.TempDir()
+	c := newResponseCache(StaleConfig{ArchiveDir: dir})
+	c.put("/old", &cachedResponse{Status: http.StatusOK, Body: []byte("old"), FetchedAt: time.Now()})
+	c.put("/new", &cachedResponse{Status: http.StatusOK, Body: []byte("new"), FetchedAt: time.Now()})
+
+	// Порядок записей архива берётся по времени изменения файлов
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, cacheID("/old")+".json"), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := newResponseCache(StaleConfig{ArchiveDir: dir, MaxEntries: 1})
+	if e, ok := reloaded.get("/new"); !ok || string(e.Body) != "new" {
+		t.Fatalf("reloaded /new = %v, %v", e, ok)
+	}
+	if _, ok := reloaded.get("/old"); ok {
+		t.Fatal("archive was not trimmed to maxEntries on load")
+	}
+	if _, err := os.Stat(filepath.Join(dir, cacheID("/old")+".json")); !os.IsNotExist(err) {
+		t.Fatalf("archive file of trimmed entry: %v, want it removed", err)
+	}
+}
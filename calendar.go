@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Календарь ближайших тиражей в формате iCalendar (RFC 5545) для подписки
+// из календарных приложений. У Stoloto есть только дата ближайшего тиража
+// каждой игры, поэтому в календаре по одному событию на игру; при следующем
+// обновлении появляется следующий тираж.
+
+// calendarRefresh — как часто календарным приложениям предлагается
+// перечитывать подписку
+const calendarRefresh = time.Hour
+
+// Calendar godoc
+// @Summary Календарь ближайших тиражей
+// @Description iCalendar (RFC 5545) с событием на ближайший тираж каждой игры и текущим суперпризом в описании. Для подписки в календарных приложениях.
+// @Tags calendar
+// @Produce text/calendar
+// @Security ApiKeyAuth
+// @Param name query []string false "Игры (можно несколько); без параметра — все" collectionFormat(multi)
+// @Success 200 {string} string "VCALENDAR"
+// @Failure 404 {object} ErrorResponse "Игра не найдена"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/calendar.ics [get]
+func handleCalendar(w http.ResponseWriter, r *http.Request) {
+	games, header, err := fetchGames(r.Context())
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+
+	selected, err := selectGames(games, r.URL.Query()["name"])
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+
+	now := time.Now()
+	hints := gamesHints(games, now)
+	// DTSTAMP берётся не из текущего времени, чтобы календарь без изменений
+	// давал тот же ETag и клиенты получали 304
+	stamp := hints.lastModified
+	if stamp.IsZero() {
+		stamp = now
+	}
+
+	var cal icalWriter
+	cal.line("BEGIN", "VCALENDAR")
+	cal.line("VERSION", "2.0")
+	cal.line("PRODID", "-//stoloto-proxy//Stoloto draws//RU")
+	cal.line("CALSCALE", "GREGORIAN")
+	cal.line("METHOD", "PUBLISH")
+	cal.line("NAME", "Тиражи Столото")
+	cal.line("X-WR-CALNAME", "Тиражи Столото")
+	cal.line("X-WR-TIMEZONE", "Europe/Moscow")
+	cal.line("REFRESH-INTERVAL;VALUE=DURATION", icalDuration(calendarRefresh))
+	cal.line("X-PUBLISHED-TTL", icalDuration(calendarRefresh))
+
+	for _, g := range selected {
+		d := g.Draw
+		if d == nil || d.Date.IsZero() || d.Date.Before(now) {
+			continue
+		}
+
+		cal.line("BEGIN", "VEVENT")
+		cal.line("UID", fmt.Sprintf("draw-%s-%d@stoloto-proxy", g.Name, d.Number))
+		cal.line("DTSTAMP", icalTime(stamp))
+		cal.line("DTSTART", icalTime(d.Date.Time))
+		cal.line("SUMMARY", icalText(fmt.Sprintf("%s: тираж №%d", g.Title, d.Number)))
+		cal.line("DESCRIPTION", icalText(fmt.Sprintf("Суперприз: %s\nИгра: %s", formatRubles(rubles(d.SuperPrize)), g.Title)))
+		cal.line("URL", drawURL(g.Name, d.Number))
+		cal.line("CATEGORIES", icalText(g.Name))
+		cal.line("TRANSP", "TRANSPARENT")
+		cal.line("END", "VEVENT")
+	}
+	cal.line("END", "VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="stoloto.ics"`)
+	serveBody(w, r, []byte(cal.String()), hints, header)
+}
+
+// selectGames оставляет игры из names в порядке списка Stoloto; пустой names — все игры
+func selectGames(games *upstreamGamesResponse, names []string) ([]upstreamGame, error) {
+	var wanted []string
+	for _, n := range names {
+		for _, part := range strings.Split(n, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				wanted = append(wanted, part)
+			}
+		}
+	}
+	if len(wanted) == 0 {
+		return games.Games, nil
+	}
+
+	for _, name := range wanted {
+		if games.find(name) == nil {
+			return nil, fmt.Errorf("%w: '%s'. Available games: %v", errGameNotFound, name, games.names())
+		}
+	}
+	var selected []upstreamGame
+	for _, g := range games.Games {
+		if slices.Contains(wanted, g.Name) {
+			selected = append(selected, g)
+		}
+	}
+	return selected, nil
+}
+
+// drawURL — публичная ссылка на тираж в API
+func drawURL(game string, number int) string {
+	return fmt.Sprintf("%s/api/games/%s/draws/%d", publicBaseURL(), game, number)
+}
+
+// formatRubles форматирует сумму с разделителями разрядов: 168 356 112 ₽
+func formatRubles(v int64) string {
+	s := strconv.FormatInt(v, 10)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String() + " ₽"
+}
+
+// icalWriter собирает iCalendar: строки через CRLF, длиннее 75 октетов
+// переносятся (RFC 5545, 3.1) без разрыва UTF-8 последовательностей
+type icalWriter struct {
+	strings.Builder
+}
+
+func (c *icalWriter) line(name, value string) {
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		c.WriteString(line[:cut])
+		c.WriteString("\r\n ")
+		line = line[cut:]
+		// Продолжение начинается с пробела, он входит в 75 октетов
+		limit = 74
+	}
+	c.WriteString(line)
+	c.WriteString("\r\n")
+}
+
+// icalText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func icalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+func icalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icalDuration — длительность в формате RFC 5545 с точностью до минут
+func icalDuration(d time.Duration) string {
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case m == 0:
+		return fmt.Sprintf("PT%dH", h)
+	case h == 0:
+		return fmt.Sprintf("PT%dM", m)
+	default:
+		return fmt.Sprintf("PT%dH%dM", h, m)
+	}
+}
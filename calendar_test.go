@@ -0,0 +1,162 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"DOUPIG/fakestoloto"
+)
+
+func TestICalWriterFolding(t *testing.T) {
+	// Кириллица — по два октета на букву, так что граница в 75 октетов
+	// приходится и на середину символа
+	value := strings.Repeat("Суперприз «Гослото» ", 12)
+	for _, prefix := range []string{"", "x"} {
+		var cal icalWriter
+		cal.line("DESCRIPTION", prefix+value)
+		out := cal.String()
+
+		if !strings.HasSuffix(out, "\r\n") || strings.Count(out, "\n") != strings.Count(out, "\r\n") {
+			t.Fatalf("lines are not CRLF-terminated: %q", out)
+		}
+		lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+		if len(lines) < 2 {
+			t.Fatalf("long line was not folded: %q", out)
+		}
+		for i, l := range lines {
+			if len(l) > 75 {
+				t.Errorf("line %d is %d octets, want at most 75", i, len(l))
+			}
+			if !utf8.ValidString(l) {
+				t.Errorf("line %d splits a UTF-8 sequence: %q", i, l)
+			}
+			if i > 0 && !strings.HasPrefix(l, " ") {
+				t.Errorf("continuation line %d does not start with a space: %q", i, l)
+			}
+		}
+		if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != "DESCRIPTION:"+prefix+value {
+			t.Fatalf("unfolded line = %q", unfolded)
+		}
+	}
+
+	var cal icalWriter
+	cal.line("UID", "short")
+	if cal.String() != "UID:short\r\n" {
+		t.Fatalf("short line = %q", cal.String())
+	}
+}
+
+func TestICalText(t *testing.T) {
+	got := icalText("a\\b;c,d\ne")
+	if want := `a\\b\;c\,d\ne`; got != want {
+		t.Fatalf("icalText = %q, want %q", got, want)
+	}
+}
+
+func TestICalDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		time.Hour:                     "PT1H",
+		15 * time.Minute:              "PT15M",
+		90 * time.Minute:              "PT1H30M",
+		26*time.Hour + 5*time.Minute:  "PT26H5M",
+		time.Hour + 30*time.Second:    "PT1H",
+		24*time.Hour + 59*time.Second: "PT24H",
+		45*time.Minute + time.Second:  "PT45M",
+	} {
+		if got := icalDuration(d); got != want {
+			t.Errorf("icalDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+var icalUID = regexp.MustCompile(`(?m)^UID:(.*)\r$`)
+
+func getCalendar(t *testing.T, url string) string {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("calendar: status %d: %s", resp.StatusCode, body)
+	}
+	return string(body)
+}
+
+func TestCalendar(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+
+	cal := getCalendar(t, proxy.URL+"/api/calendar.ics")
+	if !strings.HasPrefix(cal, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(cal, "END:VCALENDAR\r\n") {
+		t.Fatalf("not a VCALENDAR: %q", cal)
+	}
+	if strings.Count(cal, "\n") != strings.Count(cal, "\r\n") {
+		t.Fatal("calendar has bare LF line endings")
+	}
+	if !utf8.ValidString(cal) {
+		t.Fatal("calendar is not valid UTF-8")
+	}
+
+	var uids []string
+	for _, m := range icalUID.FindAllStringSubmatch(cal, -1) {
+		uids = append(uids, m[1])
+	}
+	if want := "draw-6x45-" + strconv.Itoa(fake.CurrentDraw("6x45")) + "@stoloto-proxy"; !slices.Contains(uids, want) {
+		t.Fatalf("UIDs %v do not contain %s", uids, want)
+	}
+	// Моментальные лотереи без тиражей в календарь не попадают
+	if len(uids) != 6 {
+		t.Fatalf("%d events, want one per draw game", len(uids))
+	}
+
+	// UID привязан к тиражу, а не к запросу: календарь обновляет событие, а не дублирует его
+	var again []string
+	for _, m := range icalUID.FindAllStringSubmatch(getCalendar(t, proxy.URL+"/api/calendar.ics"), -1) {
+		again = append(again, m[1])
+	}
+	if !slices.Equal(uids, again) {
+		t.Fatalf("UIDs changed between requests: %v, then %v", uids, again)
+	}
+
+	cal = getCalendar(t, proxy.URL+"/api/calendar.ics?name=7x49")
+	if uids := icalUID.FindAllStringSubmatch(cal, -1); len(uids) != 1 || !strings.HasPrefix(uids[0][1], "draw-7x49-") {
+		t.Fatalf("calendar for 7x49 has events %v", uids)
+	}
+}
+
+func TestCalendarSkipsPastDraws(t *testing.T) {
+	// Фейк, запущенный два часа назад: ближайшие тиражи частых игр уже прошли
+	past := httptest.NewServer(fakestoloto.New(time.Now().Add(-2 * time.Hour)))
+	t.Cleanup(past.Close)
+	_, proxy := startProxy(t, func(c *Config) { c.Upstream.BaseURL = past.URL + fakestoloto.APIPrefix })
+
+	cal := getCalendar(t, proxy.URL+"/api/calendar.ics")
+	for _, m := range icalUID.FindAllStringSubmatch(cal, -1) {
+		if strings.HasPrefix(m[1], "draw-5x36plus-") || strings.HasPrefix(m[1], "draw-4x20-") {
+			t.Errorf("past draw %s is in the calendar", m[1])
+		}
+	}
+	for _, m := range regexp.MustCompile(`(?m)^DTSTART:(.*)\r$`).FindAllStringSubmatch(cal, -1) {
+		start, err := time.Parse("20060102T150405Z", m[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start.Before(time.Now()) {
+			t.Errorf("event starts in the past: %s", m[1])
+		}
+	}
+}
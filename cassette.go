@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// CassetteConfig — запись и воспроизведение обменов с апстримом.
+// Mode: "" (выключено), "record" или "replay".
+type CassetteConfig struct {
+	Mode string `json:"mode"`
+	File string `json:"file"`
+}
+
+// Cassette — файл с записанными обменами
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction — один запрос к апстриму и ответ на него
+type Interaction struct {
+	RecordedAt time.Time        `json:"recordedAt"`
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	Duration   Duration         `json:"duration"`
+	// Error — сетевая ошибка вместо ответа; воспроизводится как есть
+	Error string `json:"error,omitempty"`
+}
+
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers"`
+}
+
+type RecordedResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers"`
+	// Body хранится строкой, если это UTF-8, иначе в BodyBase64
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+}
+
+var errNoRecording = errors.New("no recording in cassette")
+
+// redactedHeaders не попадают в кассету
+var redactedHeaders = []string{"Gosloto-Partner", "Authorization", "Cookie"}
+
+// cassetteTransport в режиме record пишет каждый обмен в файл,
+// в режиме replay отвечает из файла и в сеть не ходит
+type cassetteTransport struct {
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	mode     string
+	file     string
+	cassette Cassette
+	// replayed — сколько раз уже отдан ответ на каждый ключ запроса
+	replayed map[string]int
+}
+
+var upstreamCassette = &cassetteTransport{}
+
+// configure переключает режим; для replay загружает кассету
+func (t *cassetteTransport) configure(c CassetteConfig) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.mode, t.file = c.Mode, c.File
+	t.cassette = Cassette{}
+	t.replayed = make(map[string]int)
+
+	switch c.Mode {
+	case "":
+		return nil
+	case "record", "replay":
+	default:
+		return fmt.Errorf("unknown cassette mode %q, expected record or replay", c.Mode)
+	}
+	if c.File == "" {
+		return fmt.Errorf("cassette mode %q requires a file", c.Mode)
+	}
+
+	data, err := os.ReadFile(c.File)
+	if errors.Is(err, os.ErrNotExist) && c.Mode == "record" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.cassette); err != nil {
+		return fmt.Errorf("error parsing cassette: %w", err)
+	}
+	return nil
+}
+
+func cassetteKey(req *http.Request) string {
+	return req.Method + " " + req.URL.RequestURI()
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	mode := t.mode
+	t.mu.Unlock()
+
+	switch mode {
+	case "replay":
+		return t.replay(req)
+	case "record":
+		return t.record(req)
+	default:
+		return t.next.RoundTrip(req)
+	}
+}
+
+// replay отдаёт записи для одного запроса по очереди; после последней повторяет её
+func (t *cassetteTransport) replay(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := cassetteKey(req)
+	var matches []Interaction
+	for _, in := range t.cassette.Interactions {
+		if in.Request.Method+" "+in.Request.URL == key {
+			matches = append(matches, in)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w %s for %s", errNoRecording, t.file, key)
+	}
+
+	in := matches[min(t.replayed[key], len(matches)-1)]
+	t.replayed[key]++
+
+	if in.Error != "" {
+		return nil, errors.New(in.Error)
+	}
+
+	body := []byte(in.Response.Body)
+	if in.Response.BodyBase64 != "" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(in.Response.BodyBase64); err != nil {
+			return nil, fmt.Errorf("cassette body for %s is corrupted: %w", key, err)
+		}
+	}
+
+	header := in.Response.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Del("Content-Length")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+		StatusCode:    in.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// record выполняет запрос и сохраняет обмен; тело ответа читается целиком
+func (t *cassetteTransport) record(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	in := Interaction{
+		RecordedAt: start.UTC(),
+		Duration:   Duration(time.Since(start)),
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: req.Header.Clone(),
+		},
+	}
+	for _, h := range redactedHeaders {
+		in.Request.Headers.Del(h)
+	}
+
+	if err != nil {
+		in.Error = err.Error()
+	} else {
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		in.Response = RecordedResponse{Status: resp.StatusCode, Headers: resp.Header.Clone()}
+		in.Response.Headers.Del("Set-Cookie")
+		if utf8.Valid(body) {
+			in.Response.Body = string(body)
+		} else {
+			in.Response.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+		}
+	}
+
+	if saveErr := t.append(in); saveErr != nil {
+		return nil, saveErr
+	}
+	return resp, err
+}
+
+func (t *cassetteTransport) append(in Interaction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cassette.Interactions = append(t.cassette.Interactions, in)
+
+	data, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(t.file); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("error saving cassette: %w", err)
+		}
+	}
+
+	tmp := t.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error saving cassette: %w", err)
+	}
+	return os.Rename(tmp, t.file)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// useCassette переключает upstreamCassette на время теста
+func useCassette(t *testing.T, c CassetteConfig) {
+	t.Helper()
+
+	if err := upstreamCassette.configure(c); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { upstreamCassette.configure(CassetteConfig{}) })
+}
+
+func readCassette(t *testing.T, file string) Cassette {
+	t.Helper()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func getBody(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+	file := filepath.Join(t.TempDir(), "cassettes", "session.json")
+	last := fake.CurrentDraw("6x45") - 1
+
+	useCassette(t, CassetteConfig{Mode: "record", File: file})
+	paths := []string{"/api/v1/games", "/api/v1/games/6x45/draws/" + strconv.Itoa(last)}
+	recorded := make(map[string]string)
+	for _, p := range paths {
+		status, body := getBody(t, proxy.URL+p)
+		if status != http.StatusOK {
+			t.Fatalf("recording %s: status %d", p, status)
+		}
+		recorded[p] = body
+	}
+
+	cassette := readCassette(t, file)
+	if len(cassette.Interactions) != 2 {
+		t.Fatalf("cassette has %d interactions, want 2", len(cassette.Interactions))
+	}
+	for _, in := range cassette.Interactions {
+		for _, h := range redactedHeaders {
+			if in.Request.Headers.Get(h) != "" {
+				t.Fatalf("%s was recorded for %s", h, in.Request.URL)
+			}
+		}
+		if in.Response.Status != http.StatusOK || in.Response.Body == "" {
+			t.Fatalf("interaction %s: status %d, body %d bytes", in.Request.URL, in.Response.Status, len(in.Response.Body))
+		}
+	}
+
+	// Воспроизведение не ходит в сеть: апстрим уже остановлен
+	fake.Close()
+	useCassette(t, CassetteConfig{Mode: "replay", File: file})
+	for _, p := range paths {
+		status, body := getBody(t, proxy.URL+p)
+		if status != http.StatusOK || body != recorded[p] {
+			t.Fatalf("replaying %s: status %d, body differs: %v", p, status, body != recorded[p])
+		}
+	}
+
+	// Запроса нет в кассете — ошибка апстрима, а не поход в сеть
+	if status, _ := getBody(t, proxy.URL+"/api/v1/games/7x49/draws/latest"); status != http.StatusBadGateway {
+		t.Fatalf("request missing from cassette: status %d, want 502", status)
+	}
+}
+
+// stubTransport отвечает заданным телом на любой запрос
+type stubTransport struct {
+	body []byte
+}
+
+func (s stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Set-Cookie": {"session=secret"}},
+		Body:       io.NopCloser(bytes.NewReader(s.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestCassetteBinaryBodies(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "binary.json")
+	body := []byte{0x1f, 0x8b, 0xff, 0x00, 'x'}
+
+	rec := &cassetteTransport{next: stubTransport{body: body}}
+	if err := rec.configure(CassetteConfig{Mode: "record", File: file}); err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/raw", nil)
+	req.Header.Set("Gosloto-Partner", "token")
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(resp.Body); !bytes.Equal(got, body) {
+		t.Fatalf("recorded response body = %x, want %x", got, body)
+	}
+
+	in := readCassette(t, file).Interactions[0]
+	if in.Response.BodyBase64 == "" || in.Response.Body != "" {
+		t.Fatalf("binary body stored as %q / %q", in.Response.Body, in.Response.BodyBase64)
+	}
+	if in.Response.Headers.Get("Set-Cookie") != "" || in.Request.Headers.Get("Gosloto-Partner") != "" {
+		t.Fatal("cookies or partner token were recorded")
+	}
+
+	play := &cassetteTransport{}
+	if err := play.configure(CassetteConfig{Mode: "replay", File: file}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = play.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(resp.Body); !bytes.Equal(got, body) {
+		t.Fatalf("replayed body = %x, want %x", got, body)
+	}
+}
+
+func TestCassetteReplaysInOrder(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sequence.json")
+	data, err := json.Marshal(Cassette{Interactions: []Interaction{
+		{Request: RecordedRequest{Method: "GET", URL: "/draw"}, Response: RecordedResponse{Status: 503, Body: "first"}},
+		{Request: RecordedRequest{Method: "GET", URL: "/other"}, Response: RecordedResponse{Status: 200, Body: "other"}},
+		{Request: RecordedRequest{Method: "GET", URL: "/draw"}, Response: RecordedResponse{Status: 200, Body: "second"}},
+		{Request: RecordedRequest{Method: "GET", URL: "/down"}, Error: "connection refused"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	play := &cassetteTransport{}
+	if err := play.configure(CassetteConfig{Mode: "replay", File: file}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Записи одного запроса отдаются по очереди, последняя — повторяется
+	for _, want := range []string{"503 first", "200 second", "200 second"} {
+		req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/draw", nil)
+		resp, err := play.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if got := strconv.Itoa(resp.StatusCode) + " " + string(body); got != want {
+			t.Fatalf("replay = %q, want %q", got, want)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/down", nil)
+	if _, err := play.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("recorded network error replayed as %v", err)
+	}
+}
+
+func TestCassetteConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, c := range []CassetteConfig{
+		{Mode: "rewind", File: filepath.Join(dir, "c.json")},
+		{Mode: "record"},
+		{Mode: "replay", File: filepath.Join(dir, "missing.json")},
+	} {
+		if err := (&cassetteTransport{}).configure(c); err == nil {
+			t.Errorf("configure(%+v) succeeded", c)
+		}
+	}
+
+	// Для записи файла может ещё не быть
+	if err := (&cassetteTransport{}).configure(CassetteConfig{Mode: "record", File: filepath.Join(dir, "new.json")}); err != nil {
+		t.Fatal(err)
+	}
+}
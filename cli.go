@@ -0,0 +1,796 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Консольный клиент: тот же бинарник с подкомандой вместо запуска сервера.
+// Данные берутся либо напрямую у Stoloto через общий код запросов (с кешем,
+// повторами и бюджетом из конфигурации), либо у запущенного прокси через
+// API v1 (-proxy или STOLOTO_PROXY).
+
+type cliCommand struct {
+	usage   string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var cliCommands map[string]cliCommand
+
+func init() {
+	// Заполняется в init: runCLIHelp обращается к cliCommands
+	cliCommands = map[string]cliCommand{
+		"games":     {"games", "список лотерей с текущим и последним тиражом", runCLIGames},
+		"draw":      {"draw <игра> <номер>", "тираж по номеру", runCLIDraw},
+		"latest":    {"latest <игра>", "последний проведённый тираж", runCLILatest},
+		"prelatest": {"prelatest <игра>", "предпоследний проведённый тираж", runCLIPrelatest},
+		"momental":  {"momental", "моментальные лотереи", runCLIMomental},
+		"check":     {"check <игра> <номер> <числа...>", "проверка билета по результатам тиража", runCLICheck},
+		"generate":  {"generate [-n 5] <игра>", "случайные комбинации по правилам игры", runCLIGenerate},
+		"stats":     {"stats [-last 100] <игра>", "частота выпадения чисел за последние тиражи", runCLIStats},
+		"help":      {"help", "эта справка", runCLIHelp},
+	}
+}
+
+// runCLI выполняет подкоманду; false — такой подкоманды нет и нужно запускать сервер
+func runCLI(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	cmd, ok := cliCommands[args[0]]
+	if !ok {
+		return false
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := cmd.run(ctx, args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+func runCLIHelp(context.Context, []string) error {
+	names := make([]string, 0, len(cliCommands))
+	for name := range cliCommands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Подкоманды (без подкоманды запускается сервер):")
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", cliCommands[name].usage, cliCommands[name].summary)
+	}
+	fmt.Fprintln(tw, "\nОбщие флаги: -proxy URL, -api-key KEY, -config FILE, -o table|json|csv, -v")
+	return tw.Flush()
+}
+
+// cliOptions — флаги, общие для всех подкоманд
+type cliOptions struct {
+	fs      *flag.FlagSet
+	proxy   string
+	apiKey  string
+	config  string
+	format  string
+	verbose bool
+}
+
+func newCLIFlags(name string) *cliOptions {
+	o := &cliOptions{fs: flag.NewFlagSet(name, flag.ExitOnError)}
+	o.fs.StringVar(&o.proxy, "proxy", os.Getenv("STOLOTO_PROXY"), "адрес запущенного прокси; без него запросы идут напрямую к Stoloto")
+	o.fs.StringVar(&o.apiKey, "api-key", os.Getenv("STOLOTO_API_KEY"), "API-ключ прокси")
+	o.fs.StringVar(&o.config, "config", "", "конфигурация для прямых запросов (кеш, повторы, бюджет)")
+	o.fs.StringVar(&o.format, "o", "table", "формат вывода: table, json или csv")
+	o.fs.BoolVar(&o.verbose, "v", false, "печатать журнал запросов")
+	return o
+}
+
+// parse разбирает флаги и проверяет число позиционных аргументов
+func (o *cliOptions) parse(args []string, minArgs int) error {
+	o.fs.Parse(args)
+	if o.fs.NArg() < minArgs {
+		return fmt.Errorf("usage: %s", cliCommands[o.fs.Name()].usage)
+	}
+	if !slices.Contains([]string{"table", "json", "csv"}, o.format) {
+		return fmt.Errorf("unknown output format %q", o.format)
+	}
+	if !o.verbose {
+		log.SetOutput(io.Discard)
+	}
+	return nil
+}
+
+func (o *cliOptions) source() (cliSource, error) {
+	if o.proxy != "" {
+		base := strings.TrimRight(o.proxy, "/")
+		if _, err := url.ParseRequestURI(base); err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		return &proxySource{base: base, apiKey: o.apiKey, client: &http.Client{Timeout: 2 * time.Minute}}, nil
+	}
+
+	c, err := loadConfig(o.config)
+	if err != nil {
+		return nil, err
+	}
+	cfg = c
+	upstreamCache = newResponseCache(cfg.Stale)
+	upstreamBudget = newOutboundLimiter(cfg.UpstreamBudget)
+	return directSource{}, nil
+}
+
+// Источники данных
+
+type cliSource interface {
+	games(ctx context.Context) ([]GameV1, error)
+	draw(ctx context.Context, game string, number int) (DrawV1, error)
+	latest(ctx context.Context, game string) (DrawV1, error)
+	momental(ctx context.Context) ([]MomentCardV1, error)
+	// history — проведённые тиражи from..to по возрастанию номеров, без пропусков нумерации
+	history(ctx context.Context, game string, from, to int) ([]DrawV1, error)
+}
+
+type directSource struct{}
+
+func (directSource) games(ctx context.Context) ([]GameV1, error) {
+	games, _, err := fetchGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]GameV1, len(games.Games))
+	for i := range games.Games {
+		out[i] = toGameV1(&games.Games[i])
+	}
+	return out, nil
+}
+
+func (directSource) draw(ctx context.Context, game string, number int) (DrawV1, error) {
+	d, _, err := fetchDraw(ctx, game, number)
+	if err != nil {
+		return DrawV1{}, err
+	}
+	return toDrawV1(game, d), nil
+}
+
+func (directSource) latest(ctx context.Context, game string) (DrawV1, error) {
+	d, _, err := fetchLatestDraw(ctx, game)
+	if err != nil {
+		return DrawV1{}, err
+	}
+	return toDrawV1(game, d), nil
+}
+
+func (directSource) momental(ctx context.Context) ([]MomentCardV1, error) {
+	cards, _, err := fetchMomentalCards(ctx, momentalOptions{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MomentCardV1, len(cards))
+	for i := range cards {
+		out[i] = toMomentCardV1(&cards[i])
+	}
+	return out, nil
+}
+
+func (directSource) history(ctx context.Context, game string, from, to int) ([]DrawV1, error) {
+	ctx, cancel := context.WithCancel(withPriority(ctx, priorityBulk))
+
+	var out []DrawV1
+	results := prefetchDraws(ctx, game, from, to, exportPrefetch)
+	defer stopPrefetch(cancel, results)
+	for {
+		d, err := nextDraw(results)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			return out, nil
+		}
+		out = append(out, toDrawV1(game, d))
+	}
+}
+
+// proxySource обращается к запущенному прокси через API v1
+type proxySource struct {
+	base   string
+	apiKey string
+	client *http.Client
+}
+
+func (p *proxySource) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.apiKey != "" {
+		req.Header.Set("X-API-Key", p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var e ErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, e.Error)
+		}
+		return nil, errors.New(resp.Status)
+	}
+	return resp, nil
+}
+
+func (p *proxySource) getJSON(ctx context.Context, path string, v any) error {
+	resp, err := p.get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (p *proxySource) games(ctx context.Context) ([]GameV1, error) {
+	var out GamesV1
+	err := p.getJSON(ctx, "/api/v1/games", &out)
+	return out.Games, err
+}
+
+func (p *proxySource) draw(ctx context.Context, game string, number int) (DrawV1, error) {
+	var out DrawV1
+	err := p.getJSON(ctx, fmt.Sprintf("/api/v1/games/%s/draws/%d", url.PathEscape(game), number), &out)
+	return out, err
+}
+
+func (p *proxySource) latest(ctx context.Context, game string) (DrawV1, error) {
+	var out DrawV1
+	err := p.getJSON(ctx, fmt.Sprintf("/api/v1/games/%s/draws/latest", url.PathEscape(game)), &out)
+	return out, err
+}
+
+func (p *proxySource) momental(ctx context.Context) ([]MomentCardV1, error) {
+	var out MomentCardsV1
+	err := p.getJSON(ctx, "/api/v1/momental", &out)
+	return out.Cards, err
+}
+
+// history берёт диапазон одним запросом к выгрузке, а не по тиражу:
+// иначе упрётся в ограничение частоты запросов прокси
+func (p *proxySource) history(ctx context.Context, game string, from, to int) ([]DrawV1, error) {
+	q := url.Values{"name": {game}, "from": {strconv.Itoa(from)}, "to": {strconv.Itoa(to)}, "format": {"csv"}}
+	resp, err := p.get(ctx, "/api/export?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading export: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	col := map[string]int{}
+	for i, name := range records[0] {
+		col[name] = i
+	}
+	for _, name := range []string{"date", "number", "super_prize", "total_winners", "total_prize"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("export has no %q column", name)
+		}
+	}
+
+	var out []DrawV1
+	for line, rec := range records[1:] {
+		d, err := parseExportRow(game, rec, col)
+		if err != nil {
+			return nil, fmt.Errorf("export line %d: %w", line+2, err)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// parseExportRow разбирает строку CSV-выгрузки; пустые ball_N — шары, которых
+// в тираже меньше, чем в заголовке
+func parseExportRow(game string, rec []string, col map[string]int) (DrawV1, error) {
+	d := DrawV1{Game: game, Status: "completed"}
+	var err error
+	if d.Date, err = time.Parse(time.RFC3339, rec[col["date"]]); err != nil {
+		return d, fmt.Errorf("bad date: %w", err)
+	}
+
+	ints := map[string]*int{"number": &d.Number, "total_winners": &d.TotalWinners}
+	for name, dst := range ints {
+		if *dst, err = strconv.Atoi(rec[col[name]]); err != nil {
+			return d, fmt.Errorf("bad %s: %w", name, err)
+		}
+	}
+	int64s := map[string]*int64{"super_prize": &d.SuperPrize, "total_prize": &d.TotalPrize}
+	for name, dst := range int64s {
+		if *dst, err = strconv.ParseInt(rec[col[name]], 10, 64); err != nil {
+			return d, fmt.Errorf("bad %s: %w", name, err)
+		}
+	}
+
+	for i := 1; ; i++ {
+		c, ok := col[fmt.Sprintf("ball_%d", i)]
+		if !ok || rec[c] == "" {
+			break
+		}
+		n, err := strconv.Atoi(rec[c])
+		if err != nil {
+			return d, fmt.Errorf("bad ball_%d: %w", i, err)
+		}
+		d.Numbers = append(d.Numbers, n)
+	}
+	return d, nil
+}
+
+// Вывод
+
+// cliTable — табличное представление результата для table и csv;
+// json выводит исходное значение
+type cliTable struct {
+	header []string
+	rows   [][]string
+}
+
+func (o *cliOptions) print(v any, t cliTable) error {
+	switch o.format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(t.header)
+		w.WriteAll(t.rows)
+		return w.Error()
+	default:
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// time — время для таблицы в местной зоне, для csv в RFC 3339
+func (o *cliOptions) time(t time.Time) string {
+	switch {
+	case t.IsZero():
+		return ""
+	case o.format == "table":
+		return t.Local().Format("2006-01-02 15:04")
+	default:
+		return t.Format(time.RFC3339)
+	}
+}
+
+func joinInts(nums []int, sep string) string {
+	s := make([]string, len(nums))
+	for i, n := range nums {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, sep)
+}
+
+func (o *cliOptions) printDraw(d DrawV1) error {
+	t := cliTable{header: []string{"GAME", "NUMBER", "DATE", "STATUS", "NUMBERS", "SUPERPRIZE", "WINNERS", "PAID"}}
+	t.rows = append(t.rows, []string{
+		d.Game, strconv.Itoa(d.Number), o.time(d.Date), d.Status, formatCombination(d.Game, d.Numbers),
+		strconv.FormatInt(d.SuperPrize, 10), strconv.Itoa(d.TotalWinners), strconv.FormatInt(d.TotalPrize, 10),
+	})
+	if err := o.print(d, t); err != nil || o.format != "table" || len(d.Prizes) == 0 {
+		return err
+	}
+
+	// В таблице дополнительно выигрыши по категориям; в json они внутри тиража
+	fmt.Println()
+	prizes := cliTable{header: []string{"CATEGORY", "MATCHED", "WINNERS", "AMOUNT"}}
+	for _, p := range d.Prizes {
+		prizes.rows = append(prizes.rows, []string{strconv.Itoa(p.Category), p.Matched, strconv.Itoa(p.Winners), strconv.FormatInt(p.Amount, 10)})
+	}
+	return o.print(nil, prizes)
+}
+
+// Подкоманды
+
+func runCLIGames(ctx context.Context, args []string) error {
+	o := newCLIFlags("games")
+	if err := o.parse(args, 0); err != nil {
+		return err
+	}
+	src, err := o.source()
+	if err != nil {
+		return err
+	}
+	games, err := src.games(ctx)
+	if err != nil {
+		return err
+	}
+
+	t := cliTable{header: []string{"NAME", "TITLE", "TYPE", "SALES", "DRAW", "DRAW DATE", "SUPERPRIZE", "LAST DRAW"}}
+	for _, g := range games {
+		row := []string{g.Name, g.Title, g.Type, strconv.FormatBool(g.SalesOpen), "", "", "", ""}
+		if d := g.CurrentDraw; d != nil {
+			row[4], row[5], row[6] = strconv.Itoa(d.Number), o.time(d.Date), strconv.FormatInt(d.SuperPrize, 10)
+		}
+		if d := g.LastDraw; d != nil {
+			row[7] = strconv.Itoa(d.Number)
+		}
+		t.rows = append(t.rows, row)
+	}
+	return o.print(games, t)
+}
+
+func runCLIDraw(ctx context.Context, args []string) error {
+	o := newCLIFlags("draw")
+	if err := o.parse(args, 2); err != nil {
+		return err
+	}
+	number, err := strconv.Atoi(o.fs.Arg(1))
+	if err != nil || number <= 0 {
+		return fmt.Errorf("invalid draw number %q", o.fs.Arg(1))
+	}
+	src, err := o.source()
+	if err != nil {
+		return err
+	}
+	d, err := src.draw(ctx, o.fs.Arg(0), number)
+	if err != nil {
+		return err
+	}
+	return o.printDraw(d)
+}
+
+func runCLILatest(ctx context.Context, args []string) error {
+	o := newCLIFlags("latest")
+	if err := o.parse(args, 1); err != nil {
+		return err
+	}
+	src, err := o.source()
+	if err != nil {
+		return err
+	}
+	d, err := src.latest(ctx, o.fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return o.printDraw(d)
+}
+
+func runCLIPrelatest(ctx context.Context, args []string) error {
+	o := newCLIFlags("prelatest")
+	if err := o.parse(args, 1); err != nil {
+		return err
+	}
+	src, err := o.source()
+	if err != nil {
+		return err
+	}
+	latest, err := src.latest(ctx, o.fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	d, err := src.draw(ctx, o.fs.Arg(0), latest.Number-1)
+	if err != nil {
+		return err
+	}
+	return o.printDraw(d)
+}
+
+func runCLIMomental(ctx context.Context, args []string) error {
+	o := newCLIFlags("momental")
+	if err := o.parse(args, 0); err != nil {
+		return err
+	}
+	src, err := o.source()
+	if err != nil {
+		return err
+	}
+	cards, err := src.momental(ctx)
+	if err != nil {
+		return err
+	}
+
+	t := cliTable{header: []string{"ID", "NAME", "PRICE", "MAX PRIZE", "AVAILABLE"}}
+	for _, c := range cards {
+		t.rows = append(t.rows, []string{c.ID, c.Name, strconv.FormatInt(c.Price, 10), strconv.FormatInt(c.MaxPrize, 10), strconv.FormatBool(c.Available)})
+	}
+	return o.print(cards, t)
+}
+
+// ticketCheck — результат проверки билета
+type ticketCheck struct {
+	Game    string `json:"game"`
+	Draw    int    `json:"draw"`
+	Ticket  []int  `json:"ticket"`
+	Numbers []int  `json:"numbers"`
+	// Matched — угаданные числа по полям в записи выигрышных категорий: "5+1"
+	Matched  string           `json:"matched"`
+	Category *PrizeCategoryV1 `json:"category,omitempty"`
+}
+
+func runCLICheck(ctx context.Context, args []string) error {
+	o := newCLIFlags("check")
+	if err := o.parse(args, 3); err != nil {
+		return err
+	}
+	game := o.fs.Arg(0)
+	number, err := strconv.Atoi(o.fs.Arg(1))
+	if err != nil || number <= 0 {
+		return fmt.Errorf("invalid draw number %q", o.fs.Arg(1))
+	}
+	var ticket []int
+	for _, s := range o.fs.Args()[2:] {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid ticket number %q", s)
+		}
+		ticket = append(ticket, n)
+	}
+	fields, err := splitTicket(game, ticket)
+	if err != nil {
+		return err
+	}
+
+	src, err := o.source()
+	if err != nil {
+		return err
+	}
+	d, err := src.draw(ctx, game, number)
+	if err != nil {
+		return err
+	}
+	if d.Status != "completed" {
+		return fmt.Errorf("draw %d of '%s' has not been held yet", number, game)
+	}
+	drawn := splitDrawn(game, d.Numbers)
+
+	matched := make([]string, len(drawn))
+	for i := range drawn {
+		k := 0
+		for _, n := range fields[i] {
+			if slices.Contains(drawn[i], n) {
+				k++
+			}
+		}
+		matched[i] = strconv.Itoa(k)
+	}
+
+	res := ticketCheck{Game: game, Draw: number, Ticket: ticket, Numbers: d.Numbers, Matched: strings.Join(matched, "+")}
+	for i, p := range d.Prizes {
+		if p.Matched == res.Matched {
+			res.Category = &d.Prizes[i]
+		}
+	}
+
+	t := cliTable{header: []string{"GAME", "DRAW", "TICKET", "NUMBERS", "MATCHED", "CATEGORY", "PRIZE"}}
+	row := []string{game, strconv.Itoa(number), formatCombination(game, ticket), formatCombination(game, d.Numbers), res.Matched, "", "0"}
+	if res.Category != nil {
+		row[5], row[6] = strconv.Itoa(res.Category.Category), strconv.FormatInt(res.Category.Amount, 10)
+	}
+	t.rows = append(t.rows, row)
+	return o.print(res, t)
+}
+
+// splitTicket делит числа по полям билета и проверяет их по правилам игры;
+// для игр без правил всё считается одним полем. Как и у генератора, числа
+// в поле не повторяются: иначе одно число засчитывалось бы несколько раз.
+func splitTicket(game string, nums []int) ([][]int, error) {
+	rules, ok := gameRules[game]
+	if !ok {
+		if n, dup := firstDuplicate(nums); dup {
+			return nil, fmt.Errorf("number %d is repeated", n)
+		}
+		return [][]int{nums}, nil
+	}
+
+	var fields [][]int
+	rest := nums
+	for i, f := range rules {
+		if len(rest) < f.Pick {
+			return nil, fmt.Errorf("'%s' needs %d numbers, got %d", game, pickTotal(rules), len(nums))
+		}
+		field := rest[:f.Pick]
+		for _, n := range field {
+			if n < 1 || n > f.From {
+				return nil, fmt.Errorf("number %d in field %d is out of range 1..%d", n, i+1, f.From)
+			}
+		}
+		if n, dup := firstDuplicate(field); dup {
+			return nil, fmt.Errorf("number %d is repeated in field %d", n, i+1)
+		}
+		fields = append(fields, field)
+		rest = rest[f.Pick:]
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("'%s' needs %d numbers, got %d", game, pickTotal(rules), len(nums))
+	}
+	return fields, nil
+}
+
+func firstDuplicate(nums []int) (int, bool) {
+	seen := make(map[int]bool, len(nums))
+	for _, n := range nums {
+		if seen[n] {
+			return n, true
+		}
+		seen[n] = true
+	}
+	return 0, false
+}
+
+// splitDrawn делит выпавшие числа по полям без проверок. Поля, которых нет
+// в комбинации тиража, отбрасываются: тогда и категории записаны без них.
+func splitDrawn(game string, nums []int) [][]int {
+	rules, ok := gameRules[game]
+	if !ok {
+		return [][]int{nums}
+	}
+
+	var fields [][]int
+	rest := nums
+	for _, f := range rules {
+		if len(rest) == 0 {
+			break
+		}
+		n := min(f.Pick, len(rest))
+		fields = append(fields, rest[:n])
+		rest = rest[n:]
+	}
+	return fields
+}
+
+func pickTotal(rules []ruleField) int {
+	total := 0
+	for _, f := range rules {
+		total += f.Pick
+	}
+	return total
+}
+
+func runCLIGenerate(ctx context.Context, args []string) error {
+	o := newCLIFlags("generate")
+	count := o.fs.Int("n", 1, "сколько комбинаций")
+	if err := o.parse(args, 1); err != nil {
+		return err
+	}
+	game := o.fs.Arg(0)
+	rules, ok := gameRules[game]
+	if !ok {
+		return fmt.Errorf("no number rules for '%s'", game)
+	}
+	if *count <= 0 || *count > 1000 {
+		return fmt.Errorf("-n must be between 1 and 1000")
+	}
+
+	tickets := make([][]int, *count)
+	t := cliTable{header: []string{"TICKET", "NUMBERS"}}
+	for i := range tickets {
+		for _, f := range rules {
+			field := rand.Perm(f.From)[:f.Pick]
+			for j := range field {
+				field[j]++
+			}
+			slices.Sort(field)
+			tickets[i] = append(tickets[i], field...)
+		}
+		t.rows = append(t.rows, []string{strconv.Itoa(i + 1), formatCombination(game, tickets[i])})
+	}
+	return o.print(tickets, t)
+}
+
+// numberStat — сколько раз выпадало число в поле за период
+type numberStat struct {
+	Field  int     `json:"field"`
+	Number int     `json:"number"`
+	Hits   int     `json:"hits"`
+	Share  float64 `json:"share"`
+	// Ago — сколько тиражей назад выпадало последний раз; -1 — не выпадало
+	Ago int `json:"ago"`
+}
+
+func runCLIStats(ctx context.Context, args []string) error {
+	o := newCLIFlags("stats")
+	last := o.fs.Int("last", 100, "за сколько последних тиражей")
+	if err := o.parse(args, 1); err != nil {
+		return err
+	}
+	game := o.fs.Arg(0)
+	rules, ok := gameRules[game]
+	if !ok {
+		return fmt.Errorf("no number rules for '%s'", game)
+	}
+	if *last <= 0 || *last > maxExportDraws {
+		return fmt.Errorf("-last must be between 1 and %d", maxExportDraws)
+	}
+
+	src, err := o.source()
+	if err != nil {
+		return err
+	}
+	latest, err := src.latest(ctx, game)
+	if err != nil {
+		return err
+	}
+	draws, err := src.history(ctx, game, max(1, latest.Number-*last+1), latest.Number)
+	if err != nil {
+		return err
+	}
+
+	stats, err := countNumbers(game, rules, draws)
+	if err != nil {
+		return err
+	}
+
+	var out []numberStat
+	t := cliTable{header: []string{"FIELD", "NUMBER", "HITS", "SHARE", "LAST SEEN"}}
+	for _, field := range stats {
+		slices.SortStableFunc(field, func(a, b numberStat) int { return b.Hits - a.Hits })
+		for _, s := range field {
+			if len(draws) > 0 {
+				s.Share = float64(s.Hits) / float64(len(draws))
+			}
+			out = append(out, s)
+			seen := "never"
+			if s.Ago >= 0 {
+				seen = strconv.Itoa(s.Ago) + " draws ago"
+			}
+			t.rows = append(t.rows, []string{strconv.Itoa(s.Field), strconv.Itoa(s.Number), strconv.Itoa(s.Hits), fmt.Sprintf("%.1f%%", s.Share*100), seen})
+		}
+	}
+	return o.print(out, t)
+}
+
+// countNumbers считает по каждому полю, сколько раз выпадало число и сколько
+// тиражей назад оно выпало последний раз; draws — от старых к новым
+func countNumbers(game string, rules []ruleField, draws []DrawV1) ([][]numberStat, error) {
+	stats := make([][]numberStat, len(rules))
+	for i, f := range rules {
+		stats[i] = make([]numberStat, f.From)
+		for n := range stats[i] {
+			stats[i][n] = numberStat{Field: i + 1, Number: n + 1, Ago: -1}
+		}
+	}
+	// Обход от свежих тиражей к старым: первое выпадение числа — последнее по времени
+	for i, d := range slices.Backward(draws) {
+		for f, field := range splitDrawn(game, d.Numbers) {
+			for _, n := range field {
+				if n < 1 || n > rules[f].From {
+					return nil, fmt.Errorf("draw %d: number %d is outside 1..%d of field %d", d.Number, n, rules[f].From, f+1)
+				}
+				s := &stats[f][n-1]
+				s.Hits++
+				if s.Ago < 0 {
+					s.Ago = len(draws) - 1 - i
+				}
+			}
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProxySourceHistory(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+	last := fake.CurrentDraw("6x45") - 1
+
+	src := &proxySource{base: proxy.URL, client: http.DefaultClient}
+	draws, err := src.history(context.Background(), "6x45", last-4, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(draws) != 5 {
+		t.Fatalf("draws = %d, want 5", len(draws))
+	}
+	for i, d := range draws {
+		if d.Number != last-4+i || len(d.Numbers) != 6 || d.Date.IsZero() {
+			t.Fatalf("draw %d = %+v", i, d)
+		}
+	}
+}
+
+func TestProxySourceHistoryRejectsBadExport(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want string
+	}{
+		{"missing column", "date,number,ball_1\n2024-01-01T00:00:00Z,1,5\n", `no "super_prize" column`},
+		{"bad number", "date,number,ball_1,super_prize,total_winners,total_prize\n2024-01-01T00:00:00Z,x,5,0,0,0\n", "bad number"},
+		{"bad ball", "date,number,ball_1,super_prize,total_winners,total_prize\n2024-01-01T00:00:00Z,1,?,0,0,0\n", "bad ball_1"},
+		{"bad date", "date,number,ball_1,super_prize,total_winners,total_prize\nyesterday,1,5,0,0,0\n", "bad date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/csv")
+				w.Write([]byte(tt.csv))
+			}))
+			defer srv.Close()
+
+			src := &proxySource{base: srv.URL, client: srv.Client()}
+			_, err := src.history(context.Background(), "6x45", 1, 1)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountNumbers(t *testing.T) {
+	rules := gameRules["6x45"]
+	draws := []DrawV1{
+		{Number: 1, Numbers: []int{1, 2, 3, 4, 5, 6}},
+		{Number: 2, Numbers: []int{1, 10, 20, 30, 40, 45}},
+	}
+
+	stats, err := countNumbers("6x45", rules, draws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := stats[0][0]; s.Hits != 2 || s.Ago != 0 {
+		t.Fatalf("number 1 = %+v, want 2 hits, seen in the last draw", s)
+	}
+	if s := stats[0][1]; s.Hits != 1 || s.Ago != 1 {
+		t.Fatalf("number 2 = %+v, want 1 hit, seen 1 draw ago", s)
+	}
+
+	draws = append(draws, DrawV1{Number: 3, Numbers: []int{1, 2, 3, 4, 5, 46}})
+	if _, err := countNumbers("6x45", rules, draws); err == nil {
+		t.Fatal("number outside the field was accepted")
+	}
+}
+
+func TestSplitTicket(t *testing.T) {
+	for _, tc := range []struct {
+		game string
+		nums []int
+		ok   bool
+	}{
+		{"5x36plus", []int{1, 7, 12, 30, 36, 4}, true},
+		{"5x36plus", []int{7, 7, 7, 7, 7, 1}, false},
+		{"5x36plus", []int{1, 2, 3, 4, 37, 1}, false},
+		{"5x36plus", []int{1, 2, 3, 4, 5}, false},
+		// Поля независимы: одно число может быть в обоих
+		{"4x20", []int{1, 2, 3, 4, 1, 2, 3, 4}, true},
+		{"4x20", []int{1, 2, 3, 4, 5, 6, 5, 7}, false},
+		{"ruslotto", []int{5, 17, 5}, false},
+	} {
+		fields, err := splitTicket(tc.game, tc.nums)
+		if tc.ok && err != nil {
+			t.Errorf("%s %v: %v", tc.game, tc.nums, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%s %v: accepted as %v", tc.game, tc.nums, fields)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Команда fake-stoloto запускает фейковый апстрим Stoloto.
+//
+//	go run ./cmd/fake-stoloto -addr :9090 -complete-every 1m
+//
+// Прокси направляется на него через конфиг:
+//
+//	"upstream": {
+//	  "baseURL": "http://localhost:9090/p/api/mobile/api/v35",
+//	  "momentalURL": "http://localhost:9090/cms/api/moment-cards-section?platform=OS&user-segment=ALL"
+//	}
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"DOUPIG/fakestoloto"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "адрес для прослушивания")
+	latency := flag.Duration("latency", 0, "задержка каждого ответа")
+	completeEvery := flag.Duration("complete-every", 0, "как часто завершать текущие тиражи всех игр, 0 — никогда")
+	failPath := flag.String("fail-path", "", "префикс пути, на который отвечать ошибкой, например /service/draws/")
+	failStatus := flag.Int("fail-status", http.StatusServiceUnavailable, "код ответа для -fail-path")
+	noToken := flag.Bool("no-token", false, "не требовать заголовок Gosloto-Partner")
+	flag.Parse()
+
+	fake := fakestoloto.New(time.Now())
+	fake.SetLatency(*latency)
+	fake.RequireToken = !*noToken
+	if *failPath != "" {
+		fake.InjectFault(fakestoloto.Fault{Path: *failPath, Status: *failStatus})
+	}
+
+	if *completeEvery > 0 {
+		go func() {
+			for range time.Tick(*completeEvery) {
+				fake.CompleteAll()
+				log.Println("Completed current draws")
+			}
+		}()
+	}
+
+	log.Printf("Fake Stoloto listening on %s", *addr)
+	log.Printf("upstream.baseURL = http://localhost%s%s", *addr, fakestoloto.APIPrefix)
+	log.Fatal(http.ListenAndServe(*addr, fake))
+}
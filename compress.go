@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionConfig — сжатие ответов по Accept-Encoding.
+// Encodings перечислены в порядке предпочтения сервера.
+type CompressionConfig struct {
+	Enabled   bool     `json:"enabled"`
+	Encodings []string `json:"encodings"`
+	// MinSize — ответы меньше этого размера (по Content-Length) не сжимаются
+	MinSize int `json:"minSize"`
+}
+
+// compressibleTypes — типы содержимого, которые имеет смысл сжимать
+var compressibleTypes = []string{"application/json", "application/xml", "application/javascript",
+	"application/atom+xml", "application/rss+xml", "text/"}
+
+// withCompression сжимает ответ кодировкой, выбранной по Accept-Encoding.
+// ETag сжатого представления получает суффикс кодировки, чтобы CDN
+// не перепутали его с несжатым; суффикс снимается с If-None-Match до handler.
+func withCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.Compression.Enabled || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		enc := negotiateEncoding(r.Header.Get("Accept-Encoding"), cfg.Compression.Encodings)
+		if enc == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: enc}
+		if inm := r.Header.Get("If-None-Match"); strings.Contains(inm, "-"+enc+`"`) {
+			cw.suffixedValidator = true
+			r.Header.Set("If-None-Match", strings.ReplaceAll(inm, "-"+enc+`"`, `"`))
+		}
+
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding выбирает первую из supported, которую клиент принимает с q > 0
+func negotiateEncoding(header string, supported []string) string {
+	if header == "" {
+		return ""
+	}
+
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	for _, enc := range supported {
+		q, ok := accepted[enc]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > 0 {
+			return enc
+		}
+	}
+	return ""
+}
+
+// compressWriter решает, сжимать ли ответ, в момент записи заголовков
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	enc         io.WriteCloser
+	wroteHeader bool
+	// suffixedValidator — клиент прислал ETag сжатого представления
+	suffixedValidator bool
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	h := cw.Header()
+	compress := cw.shouldCompress(code)
+	etag := h.Get("ETag")
+	if strings.HasSuffix(etag, `"`) && (compress || (code == http.StatusNotModified && cw.suffixedValidator)) {
+		h.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+cw.encoding+`"`)
+	}
+
+	if compress {
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		h.Set("Content-Encoding", cw.encoding)
+		cw.enc = newEncoder(cw.encoding, cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *compressWriter) shouldCompress(code int) bool {
+	h := cw.Header()
+	if code < 200 || code == http.StatusNoContent || code == http.StatusNotModified || code == http.StatusPartialContent {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < cfg.Compression.MinSize {
+		return false
+	}
+
+	ct, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	return slices.ContainsFunc(compressibleTypes, func(t string) bool {
+		return ct == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(ct, t))
+	})
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush отправляет клиенту уже сжатые данные; нужен для потоковых ответов
+func (cw *compressWriter) Flush() {
+	if f, ok := cw.enc.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("response writer does not support hijacking")
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) Close() error {
+	if cw.enc == nil {
+		return nil
+	}
+	return cw.enc.Close()
+}
+
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	switch encoding {
+	case "zstd":
+		enc, _ := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		return enc
+	case "br":
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	default:
+		enc, _ := gzip.NewWriterLevel(w, gzip.DefaultCompression)
+		return enc
+	}
+}
+
+// decompressTransport запрашивает у апстрима сжатые ответы и прозрачно
+// их распаковывает: выше по цепочке тело всегда несжатое, а Content-Encoding
+// и Content-Length удалены
+type decompressTransport struct {
+	next http.RoundTripper
+}
+
+func (t *decompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", "zstd, br, gzip")
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	var body io.ReadCloser
+	switch encoding {
+	case "", "identity":
+		return resp, nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("error decoding gzip response: %w", err)
+		}
+		body = gz
+	case "br":
+		body = io.NopCloser(brotli.NewReader(resp.Body))
+	case "zstd":
+		dec, err := zstd.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("error decoding zstd response: %w", err)
+		}
+		body = dec.IOReadCloser()
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("unsupported upstream Content-Encoding %q", encoding)
+	}
+
+	resp.Body = &decodedBody{Reader: body, decoder: body, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// decodedBody закрывает и распаковщик, и исходное тело
+type decodedBody struct {
+	io.Reader
+	decoder io.Closer
+	raw     io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	b.decoder.Close()
+	return b.raw.Close()
+}
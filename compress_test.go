@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{"zstd", "br", "gzip"}
+	for header, want := range map[string]string{
+		"":                        "",
+		"identity":                "",
+		"gzip":                    "gzip",
+		"gzip, deflate, br":       "br",
+		"GZIP, BR;q=0":            "gzip",
+		"br;q=0.5, zstd;q=0.1":    "zstd",
+		"zstd;q=0, br;q=0, gzip":  "gzip",
+		"*":                       "zstd",
+		"*;q=0":                   "",
+		"zstd;q=0, *":             "br",
+		" gzip ; q=1 , br ; q=0 ": "gzip",
+	} {
+		if got := negotiateEncoding(header, supported); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+// decode распаковывает тело независимо от decompressTransport
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var r io.Reader
+	var err error
+	switch encoding {
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		var dec *zstd.Decoder
+		dec, err = zstd.NewReader(bytes.NewReader(body))
+		if err == nil {
+			defer dec.Close()
+		}
+		r = dec
+	default:
+		return string(body)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", encoding, err)
+	}
+	return string(out)
+}
+
+// serveCompressed прогоняет запрос через withCompression к обработчику,
+// отдающему body с ETag и Content-Length
+func serveCompressed(t *testing.T, r *http.Request, contentType, body string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+
+	prev := cfg
+	t.Cleanup(func() { cfg = prev })
+	cfg.Compression = defaultConfig().Compression
+
+	var seenINM string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenINM = r.Header.Get("If-None-Match")
+		w.Header().Set("ETag", `"abc"`)
+		if seenINM == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		io.WriteString(w, body)
+	})
+	rec := httptest.NewRecorder()
+	withCompression(handler).ServeHTTP(rec, r)
+	return rec, seenINM
+}
+
+func TestCompression(t *testing.T) {
+	large := `{"draws":[` + strings.Repeat(`{"number":1},`, 200) + `{}]}`
+
+	for _, enc := range []string{"zstd", "br", "gzip"} {
+		t.Run(enc, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/games", nil)
+			r.Header.Set("Accept-Encoding", enc)
+			rec, _ := serveCompressed(t, r, "application/json; charset=utf-8", large)
+
+			h := rec.Header()
+			if h.Get("Content-Encoding") != enc || h.Get("Content-Length") != "" || h.Get("Vary") != "Accept-Encoding" {
+				t.Fatalf("headers %v", h)
+			}
+			if got, want := h.Get("ETag"), `"abc-`+enc+`"`; got != want {
+				t.Fatalf("ETag = %s, want %s", got, want)
+			}
+			if got := decode(t, enc, rec.Body.Bytes()); got != large {
+				t.Fatalf("decoded body differs: %d bytes, want %d", len(got), len(large))
+			}
+
+			// ETag сжатого представления совпадает с несжатым для handler
+			r.Header.Set("If-None-Match", `"abc-`+enc+`"`)
+			rec, seen := serveCompressed(t, r, "application/json", large)
+			if seen != `"abc"` || rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != `"abc-`+enc+`"` {
+				t.Fatalf("conditional request: handler saw %s, status %d, ETag %s", seen, rec.Code, rec.Header().Get("ETag"))
+			}
+		})
+	}
+
+	for name, tc := range map[string]struct {
+		accept, contentType, body string
+	}{
+		"not accepted": {"identity", "application/json", large},
+		"small body":   {"gzip", "application/json", `{"draws":[]}`},
+		"binary type":  {"gzip", "image/png", large},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/games", nil)
+			r.Header.Set("Accept-Encoding", tc.accept)
+			rec, _ := serveCompressed(t, r, tc.contentType, tc.body)
+
+			h := rec.Header()
+			if h.Get("Content-Encoding") != "" || h.Get("ETag") != `"abc"` || rec.Body.String() != tc.body {
+				t.Fatalf("response was altered: headers %v", h)
+			}
+			if h.Get("Content-Length") != strconv.Itoa(len(tc.body)) {
+				t.Fatalf("Content-Length = %q, want %d", h.Get("Content-Length"), len(tc.body))
+			}
+		})
+	}
+}
+
+// encodedTransport отвечает телом, сжатым кодировкой encoding
+type encodedTransport struct {
+	encoding string
+	body     []byte
+	accept   string
+}
+
+func (s *encodedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.accept = req.Header.Get("Accept-Encoding")
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Encoding": {s.encoding},
+			"Content-Length":   {strconv.Itoa(len(s.body))},
+		},
+		ContentLength: int64(len(s.body)),
+		Body:          io.NopCloser(bytes.NewReader(s.body)),
+		Request:       req,
+	}, nil
+}
+
+func TestDecompressTransport(t *testing.T) {
+	const payload = `{"requestStatus":"success","games":[]}`
+
+	for _, enc := range []string{"zstd", "br", "gzip", "identity"} {
+		var buf bytes.Buffer
+		if enc == "identity" {
+			buf.WriteString(payload)
+		} else {
+			w := newEncoder(enc, &buf)
+			io.WriteString(w, payload)
+			w.Close()
+		}
+
+		stub := &encodedTransport{encoding: enc, body: buf.Bytes()}
+		req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/games", nil)
+		resp, err := (&decompressTransport{next: stub}).RoundTrip(req)
+		if err != nil {
+			t.Fatalf("%s: %v", enc, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || string(body) != payload {
+			t.Fatalf("%s: body %q, %v", enc, body, err)
+		}
+		if stub.accept != "zstd, br, gzip" {
+			t.Fatalf("upstream Accept-Encoding = %q", stub.accept)
+		}
+		if enc != "identity" && (resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Content-Length") != "" || resp.ContentLength != -1) {
+			t.Fatalf("%s: encoding headers left: %v, length %d", enc, resp.Header, resp.ContentLength)
+		}
+	}
+
+	// Неизвестная кодировка — ошибка, а запрос вызывающего не меняется
+	req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/games", nil)
+	stub := &encodedTransport{encoding: "deflate", body: []byte("x")}
+	if _, err := (&decompressTransport{next: stub}).RoundTrip(req); err == nil {
+		t.Fatal("unsupported Content-Encoding was accepted")
+	}
+	if req.Header.Get("Accept-Encoding") != "" {
+		t.Fatal("caller's request was modified")
+	}
+
+	stub = &encodedTransport{encoding: "gzip", body: []byte("not gzip")}
+	if _, err := (&decompressTransport{next: stub}).RoundTrip(req); err == nil {
+		t.Fatal("corrupt gzip body was accepted")
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config содержит настройки прокси. Загружается из JSON-файла, путь к которому
+// передаётся флагом -config; поля, отсутствующие в файле, берутся из defaultConfig.
+type Config struct {
+	Upstream  UpstreamConfig  `json:"upstream"`
+	Server    ServerConfig    `json:"server"`
+	TLS       TLSConfig       `json:"tls"`
+	Retry     RetryConfig     `json:"retry"`
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	Auth      AuthConfig      `json:"auth"`
+	// UpstreamBudget — общий лимит запросов к Stoloto со всех источников
+	UpstreamBudget UpstreamBudgetConfig `json:"upstreamBudget"`
+	CORS           CORSConfig           `json:"cors"`
+	Cassette       CassetteConfig       `json:"cassette"`
+	Stale          StaleConfig          `json:"staleIfError"`
+	SWR            SWRConfig            `json:"staleWhileRevalidate"`
+	HTTPCache      HTTPCacheConfig      `json:"httpCache"`
+	Compression    CompressionConfig    `json:"compression"`
+	Headers        HeaderPolicyConfig   `json:"headers"`
+	GRPC           GRPCConfig           `json:"grpc"`
+	DrawEvents     DrawEventsConfig     `json:"drawEvents"`
+	// MomentalChanges — журнал изменений витрины моментальных лотерей
+	MomentalChanges MomentalChangesConfig `json:"momentalChanges"`
+}
+
+// UpstreamConfig — адреса Stoloto; для разработки их можно направить на fake-stoloto
+type UpstreamConfig struct {
+	BaseURL     string `json:"baseURL"`
+	MomentalURL string `json:"momentalURL"`
+	// MomentalPlatforms и MomentalUserSegments — значения platform и
+	// userSegment, которые клиенты могут передать CMS. Каждое значение —
+	// отдельный ключ кеша, поэтому принимаются только перечисленные.
+	MomentalPlatforms    []string `json:"momentalPlatforms"`
+	MomentalUserSegments []string `json:"momentalUserSegments"`
+}
+
+// cfg — текущая конфигурация, заполняется в main до регистрации handlers
+var cfg = defaultConfig()
+
+func defaultConfig() Config {
+	return Config{
+		Upstream: UpstreamConfig{
+			BaseURL:              "https://www.stoloto.ru/p/api/mobile/api/v35",
+			MomentalURL:          "https://api.stoloto.ru/cms/api/moment-cards-section?platform=OS&user-segment=ALL",
+			MomentalPlatforms:    []string{"OS", "IOS", "ANDROID"},
+			MomentalUserSegments: []string{"ALL"},
+		},
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadTimeout:       Duration(15 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(45 * time.Second),
+			IdleTimeout:       Duration(2 * time.Minute),
+			MaxHeaderBytes:    64 << 10,
+			ShutdownTimeout:   Duration(20 * time.Second),
+		},
+		TLS: TLSConfig{
+			ReloadInterval: Duration(10 * time.Second),
+		},
+		Retry: RetryConfig{
+			Default: RetryPolicy{
+				MaxAttempts: 3,
+				BaseDelay:   Duration(200 * time.Millisecond),
+				MaxDelay:    Duration(2 * time.Second),
+				Budget:      Duration(10 * time.Second),
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Routes: map[string]RouteRateLimit{
+				"/api/": {
+					PerIP:  RateLimit{PerMinute: 60, Burst: 30},
+					PerKey: RateLimit{PerMinute: 600, Burst: 100},
+				},
+				// Один запрос GraphQL может стоить десятков запросов к апстриму
+				"/graphql": {
+					PerIP:  RateLimit{PerMinute: 20, Burst: 10},
+					PerKey: RateLimit{PerMinute: 200, Burst: 50},
+				},
+			},
+		},
+		Stale: StaleConfig{
+			Default: Duration(time.Hour),
+			MaxStale: map[string]Duration{
+				// Завершённые тиражи не меняются, их можно отдавать сколько угодно долго
+				"/service/draws/":               Duration(30 * 24 * time.Hour),
+				"/service/games/info-new":       Duration(time.Hour),
+				"/cms/api/moment-cards-section": Duration(24 * time.Hour),
+			},
+			MaxEntries: 20000,
+			MaxBytes:   128 << 20,
+		},
+		SWR: SWRConfig{
+			Warmup: true,
+			Routes: map[string]SWRPolicy{
+				"/service/games/info-new":       {SoftTTL: Duration(30 * time.Second), HardTTL: Duration(10 * time.Minute)},
+				"/cms/api/moment-cards-section": {SoftTTL: Duration(5 * time.Minute), HardTTL: Duration(time.Hour)},
+			},
+		},
+		HTTPCache: HTTPCacheConfig{
+			GamesMaxAge:     Duration(30 * time.Second),
+			DrawMaxAge:      Duration(time.Minute),
+			MomentalMaxAge:  Duration(5 * time.Minute),
+			CompletedMaxAge: Duration(365 * 24 * time.Hour),
+		},
+		Compression: CompressionConfig{
+			Enabled:   true,
+			Encodings: []string{"zstd", "br", "gzip"},
+			MinSize:   1024,
+		},
+		DrawEvents: DrawEventsConfig{
+			PollInterval: Duration(30 * time.Second),
+		},
+		MomentalChanges: MomentalChangesConfig{
+			PollInterval: Duration(10 * time.Minute),
+			MaxChanges:   1000,
+		},
+		Headers: HeaderPolicyConfig{
+			Allow: []string{"Content-Type", "Content-Language"},
+			Deny:  []string{"Server", "X-*"},
+			Via:   "stoloto-proxy",
+		},
+		UpstreamBudget: UpstreamBudgetConfig{
+			RequestsPerSecond: 10,
+			Burst:             20,
+			MaxQueue:          100,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "HEAD", "POST", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type", "X-API-Key"},
+			ExposedHeaders: []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+			MaxAge:         Duration(10 * time.Minute),
+		},
+		Auth: AuthConfig{
+			KeysFile: "apikeys.json",
+			Routes: map[string]string{
+				"/api/":       scopeDrawsRead,
+				"/api/admin/": scopeAdmin,
+				"/graphql":    scopeDrawsRead,
+			},
+		},
+	}
+}
+
+func loadConfig(path string) (Config, error) {
+	c := defaultConfig()
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("error parsing config: %w", err)
+	}
+
+	return c, nil
+}
+
+// Duration — time.Duration, который в JSON записывается строкой вида "1.5s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"500ms\": %w", err)
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+// longestPrefix возвращает значение с самым длинным ключом-префиксом path
+func longestPrefix[T any](m map[string]T, path string) (T, bool) {
+	var value T
+	longest := -1
+
+	for prefix, v := range m {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			longest = len(prefix)
+			value = v
+		}
+	}
+
+	return value, longest >= 0
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSOriginAllowed(t *testing.T) {
+	c := CORSConfig{AllowedOrigins: []string{"https://app.example.ru", "https://*.partner.ru"}}
+	for origin, want := range map[string]bool{
+		"https://app.example.ru":   true,
+		"https://APP.example.ru":   true,
+		"https://evil.example.ru":  false,
+		"http://app.example.ru":    false,
+		"https://a.partner.ru":     true,
+		"https://a.b.partner.ru":   true,
+		"https://partner.ru":       false,
+		"https://evilpartner.ru":   false,
+		"http://a.partner.ru":      false,
+		"https://a.partner.ru.com": false,
+	} {
+		if got := c.originAllowed(origin); got != want {
+			t.Errorf("originAllowed(%q) = %v, want %v", origin, got, want)
+		}
+	}
+
+	if !(CORSConfig{AllowedOrigins: []string{"*"}}).originAllowed("https://any.example") {
+		t.Error(`"*" does not allow an arbitrary origin`)
+	}
+}
+
+// serveCORS прогоняет запрос через withCORS и сообщает, дошёл ли он до обработчика
+func serveCORS(t *testing.T, c CORSConfig, r *http.Request) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+
+	prev := cfg
+	t.Cleanup(func() { cfg = prev })
+	cfg.CORS = c
+
+	called := false
+	rec := httptest.NewRecorder()
+	withCORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })).ServeHTTP(rec, r)
+	return rec, called
+}
+
+func TestCORS(t *testing.T) {
+	c := defaultConfig().CORS
+	c.AllowedOrigins = []string{"https://app.example.ru"}
+
+	get := func(path, origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+	preflight := func(origin, method string) *http.Request {
+		r := httptest.NewRequest(http.MethodOptions, "/api/v1/games", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		return r
+	}
+
+	t.Run("simple request", func(t *testing.T) {
+		rec, called := serveCORS(t, c, get("/api/v1/games", "https://app.example.ru"))
+		h := rec.Header()
+		if !called || h.Get("Access-Control-Allow-Origin") != "https://app.example.ru" || h.Get("Vary") != "Origin" {
+			t.Fatalf("called %v, headers %v", called, h)
+		}
+		if h.Get("Access-Control-Expose-Headers") == "" || h.Get("Access-Control-Allow-Credentials") != "" {
+			t.Fatalf("headers %v", h)
+		}
+	})
+
+	t.Run("unknown origin", func(t *testing.T) {
+		rec, called := serveCORS(t, c, get("/api/v1/games", "https://evil.example"))
+		if !called || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatalf("called %v, headers %v", called, rec.Header())
+		}
+	})
+
+	t.Run("outside api", func(t *testing.T) {
+		rec, called := serveCORS(t, c, get("/swagger/index.html", "https://app.example.ru"))
+		if !called || rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Header().Get("Vary") != "" {
+			t.Fatalf("called %v, headers %v", called, rec.Header())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		rec, called := serveCORS(t, CORSConfig{}, get("/api/v1/games", "https://app.example.ru"))
+		if !called || len(rec.Header()) != 0 {
+			t.Fatalf("called %v, headers %v", called, rec.Header())
+		}
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		wild := c
+		wild.AllowedOrigins = []string{"*"}
+		rec, _ := serveCORS(t, wild, get("/api/v1/games", "https://any.example"))
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Fatalf("Allow-Origin = %q, want *", got)
+		}
+
+		// С credentials "*" запрещён, возвращается сам origin
+		wild.AllowCredentials = true
+		rec, _ = serveCORS(t, wild, get("/api/v1/games", "https://any.example"))
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://any.example" || rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Fatalf("headers with credentials %v", rec.Header())
+		}
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		rec, called := serveCORS(t, c, preflight("https://app.example.ru", http.MethodPost))
+		h := rec.Header()
+		if called || rec.Code != http.StatusNoContent {
+			t.Fatalf("preflight: called %v, status %d", called, rec.Code)
+		}
+		if h.Get("Access-Control-Allow-Methods") == "" || h.Get("Access-Control-Allow-Headers") != "Content-Type, X-API-Key" {
+			t.Fatalf("preflight headers %v", h)
+		}
+		if got, want := h.Get("Access-Control-Max-Age"), "600"; got != want {
+			t.Fatalf("Max-Age = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("preflight method not allowed", func(t *testing.T) {
+		rec, called := serveCORS(t, c, preflight("https://app.example.ru", http.MethodPut))
+		if called || rec.Code != http.StatusForbidden {
+			t.Fatalf("called %v, status %d", called, rec.Code)
+		}
+	})
+
+	t.Run("preflight unknown origin", func(t *testing.T) {
+		rec, called := serveCORS(t, c, preflight("https://evil.example", http.MethodGet))
+		if called || rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatalf("called %v, status %d, headers %v", called, rec.Code, rec.Header())
+		}
+	})
+}
+
+func TestCORSPreflightSkipsAuth(t *testing.T) {
+	_, proxy := startProxy(t, func(c *Config) { c.CORS.AllowedOrigins = []string{"https://app.example.ru"} })
+	withAPIKeys(t)
+
+	req, err := http.NewRequest(http.MethodOptions, proxy.URL+"/api/v1/games", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Origin", "https://app.example.ru")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	req.Header.Set("Access-Control-Request-Headers", "x-api-key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "https://app.example.ru" {
+		t.Fatalf("preflight without API key: status %d, headers %v", resp.StatusCode, resp.Header)
+	}
+
+	// Сам запрос без ключа по-прежнему отклоняется
+	if resp := getJSON(t, proxy.URL+"/api/v1/games", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("request without API key: status %d, want 401", resp.StatusCode)
+	}
+}
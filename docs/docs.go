@@ -15,38 +15,49 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/draw/": {
+        "/api/admin/keys": {
             "get": {
-                "description": "Возвращает данные о конкретном розыгрыше по имени игры и номеру",
+                "description": "GET — список ключей, POST — выпуск нового ключа, DELETE ?id= — отзыв ключа. Требуется scope admin.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "draws"
+                    "admin"
                 ],
-                "summary": "Получить информацию о конкретном розыгрыше",
+                "summary": "Управление API-ключами",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Название игры (например: 5x36, 6x45)",
-                        "name": "name",
-                        "in": "query",
-                        "required": true
+                        "description": "ID ключа для DELETE",
+                        "name": "id",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Номер розыгрыша",
-                        "name": "number",
-                        "in": "query",
-                        "required": true
+                        "description": "Параметры нового ключа для POST",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAPIKeyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ",
+                        "description": "Список ключей",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.APIKey"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Выпущенный ключ",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -55,119 +66,283 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка сервера",
+                    "401": {
+                        "description": "Нет или неверный API-ключ",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ключ не найден",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/api/draw/latest": {
-            "get": {
-                "description": "Возвращает данные последнего розыгрыша для указанной игры",
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "GET — список ключей, POST — выпуск нового ключа, DELETE ?id= — отзыв ключа. Требуется scope admin.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "draws"
+                    "admin"
                 ],
-                "summary": "Получить последний розыгрыш для игры",
+                "summary": "Управление API-ключами",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Название игры (например: 5x36, 6x45)",
-                        "name": "name",
-                        "in": "query",
-                        "required": true
+                        "description": "ID ключа для DELETE",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Параметры нового ключа для POST",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAPIKeyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ",
+                        "description": "Список ключей",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.APIKey"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Выпущенный ключ",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAPIKeyResponse"
                         }
                     },
                     "400": {
-                        "description": "Не указано имя игры",
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Нет или неверный API-ключ",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Игра не найдена",
+                        "description": "Ключ не найден",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "GET — список ключей, POST — выпуск нового ключа, DELETE ?id= — отзыв ключа. Требуется scope admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Управление API-ключами",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID ключа для DELETE",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Параметры нового ключа для POST",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список ключей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.APIKey"
+                            }
+                        }
                     },
-                    "500": {
-                        "description": "Ошибка сервера",
+                    "201": {
+                        "description": "Выпущенный ключ",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Нет или неверный API-ключ",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ключ не найден",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         },
-        "/api/draw/momental": {
+        "/api/calendar.ics": {
             "get": {
-                "description": "Возвращает информацию о всех моментальных играх",
+                "description": "iCalendar (RFC 5545) с событием на ближайший тираж каждой игры и текущим суперпризом в описании. Для подписки в календарных приложениях.",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "draws"
+                    "calendar"
+                ],
+                "summary": "Календарь ближайших тиражей",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Игры (можно несколько); без параметра — все",
+                        "name": "name",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Получить список всех моментальных",
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ",
+                        "description": "VCALENDAR",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка сервера",
+                    "404": {
+                        "description": "Игра не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         },
-        "/api/draw/prelatest": {
+        "/api/export": {
             "get": {
-                "description": "Возвращает данные последнего розыгрыша для указанной игры",
+                "description": "Тиражи игры из диапазона номеров в CSV или Parquet: дата, номер, шары, суперприз, победители и выигрыши по категориям. Без from — последние 100 тиражей, без to — до последнего проведённого.",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.apache.parquet"
                 ],
                 "tags": [
-                    "draws"
+                    "export"
                 ],
-                "summary": "Получить предпоследний розыгрыш для игры",
+                "summary": "Выгрузка истории тиражей",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Название игры (например: 5x36, 6x45)",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
                         "name": "name",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Первый тираж",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Последний тираж",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ",
+                        "description": "Файл выгрузки",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Не указано имя игры",
+                        "description": "Неверные параметры",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -178,54 +353,1877 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка сервера",
+                    "502": {
+                        "description": "Ошибка апстрима",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         },
-        "/api/draws/": {
+        "/api/feed.atom": {
             "get": {
-                "description": "Возвращает информацию о всех доступных играх",
+                "description": "Последние проведённые тиражи с выигрышной комбинацией, суперпризом и победителями. Без name — по всем играм.",
                 "produces": [
-                    "application/json"
+                    "application/atom+xml"
                 ],
                 "tags": [
-                    "draws"
+                    "feed"
+                ],
+                "summary": "Лента результатов тиражей (Atom)",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Игры (можно несколько); без параметра — все",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Сколько тиражей в ленте (до 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Получить список всех игр",
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ",
+                        "description": "Atom feed",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка сервера",
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Игра не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
-        }
-    },
-    "definitions": {
-        "main.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
+        },
+        "/api/feed.rss": {
+            "get": {
+                "description": "То же, что /api/feed.atom, в формате RSS 2.0.",
+                "produces": [
+                    "application/rss+xml"
+                ],
+                "tags": [
+                    "feed"
+                ],
+                "summary": "Лента результатов тиражей (RSS)",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Игры (можно несколько); без параметра — все",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Сколько тиражей в ленте (до 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "RSS feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Игра не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
                 },
-                "success": {
-                    "type": "boolean"
-                }
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
+        },
+        "/api/games": {
+            "get": {
+                "description": "Лотереи с текущим и последним проведённым тиражом. Фильтры объединяются через «и»; без limit возвращается весь список.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Список лотерей",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "draw",
+                                "bingo",
+                                "instant"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Тип лотереи",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Есть текущий тираж",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Продажи открыты",
+                        "name": "salesOpen",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подстрока или шаблон (*, ?) имени или названия, без учёта регистра",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "superPrize",
+                            "-superPrize",
+                            "nextDraw",
+                            "-nextDraw"
+                        ],
+                        "type": "string",
+                        "description": "Сортировка; минус — по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (до 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы (только для того же sort)",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.GamesV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Апстрим не ответил вовремя",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/games/{name}": {
+            "get": {
+                "description": "Лотерея с текущим и последним проведённым тиражом",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Лотерея",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.GameV1"
+                        }
+                    },
+                    "404": {
+                        "description": "Игра не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/games/{name}/draws/latest": {
+            "get": {
+                "description": "Последний тираж игры, по которому известны результаты",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Последний проведённый тираж",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DrawV1"
+                        }
+                    },
+                    "404": {
+                        "description": "Игра не найдена или ещё не проводилась",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/games/{name}/draws/{number}": {
+            "get": {
+                "description": "Тираж по названию игры и номеру",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Тираж",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер тиража",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DrawV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный номер тиража",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тираж не найден",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/momental": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Моментальные лотереи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "OS",
+                        "description": "Платформа витрины CMS",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ALL",
+                        "description": "Сегмент пользователей витрины CMS",
+                        "name": "userSegment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MomentCardsV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/momental/cards": {
+            "get": {
+                "description": "Карточки моментальных лотерей с поиском по названию, фильтрами по цене билета и максимальному выигрышу и сортировкой. Суммы в рублях, границы включаются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Поиск моментальных лотерей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Подстрока названия, без учёта регистра",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Доступна для покупки",
+                        "name": "available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Цена билета от",
+                        "name": "priceFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Цена билета до",
+                        "name": "priceTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальный выигрыш от",
+                        "name": "prizeFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальный выигрыш до",
+                        "name": "prizeTo",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "price",
+                            "-price",
+                            "maxPrize",
+                            "-maxPrize"
+                        ],
+                        "type": "string",
+                        "description": "Сортировка; минус — по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "OS",
+                        "description": "Платформа витрины CMS",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ALL",
+                        "description": "Сегмент пользователей витрины CMS",
+                        "name": "userSegment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MomentCardsV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/momental/cards/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Моментальная лотерея",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор карточки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "OS",
+                        "description": "Платформа витрины CMS",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ALL",
+                        "description": "Сегмент пользователей витрины CMS",
+                        "name": "userSegment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MomentCardV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Карточка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/momental/changes": {
+            "get": {
+                "description": "Добавленные, удалённые и изменившие цену или максимальный выигрыш карточки по сравнению снимков витрины. Без since — весь хранимый журнал.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Изменения витрины моментальных лотерей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Только изменения позже этого момента (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MomentalChangesV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Отслеживание отключено",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/raw/games": {
+            "get": {
+                "description": "Возвращает информацию о всех доступных играх",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draws"
+                ],
+                "summary": "Получить список всех игр",
+                "responses": {
+                    "200": {
+                        "description": "Успешный ответ",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/raw/games/{name}/draws/latest": {
+            "get": {
+                "description": "Возвращает данные последнего розыгрыша для указанной игры",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draws"
+                ],
+                "summary": "Получить последний розыгрыш для игры",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный ответ",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Не указано имя игры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Игра не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/raw/games/{name}/draws/prelatest": {
+            "get": {
+                "description": "Возвращает данные последнего розыгрыша для указанной игры",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draws"
+                ],
+                "summary": "Получить предпоследний розыгрыш для игры",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный ответ",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Не указано имя игры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Игра не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/raw/games/{name}/draws/{number}": {
+            "get": {
+                "description": "Возвращает данные о конкретном розыгрыше по имени игры и номеру",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draws"
+                ],
+                "summary": "Получить информацию о конкретном розыгрыше",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Номер розыгрыша",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный ответ",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/raw/momental": {
+            "get": {
+                "description": "Возвращает информацию о всех моментальных играх",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draws"
+                ],
+                "summary": "Получить список всех моментальных",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "OS",
+                        "description": "Платформа витрины CMS",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ALL",
+                        "description": "Сегмент пользователей витрины CMS",
+                        "name": "userSegment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный ответ",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/games": {
+            "get": {
+                "description": "Лотереи с текущим и последним проведённым тиражом. Фильтры объединяются через «и»; без limit возвращается весь список.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Список лотерей",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "draw",
+                                "bingo",
+                                "instant"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Тип лотереи",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Есть текущий тираж",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Продажи открыты",
+                        "name": "salesOpen",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подстрока или шаблон (*, ?) имени или названия, без учёта регистра",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "superPrize",
+                            "-superPrize",
+                            "nextDraw",
+                            "-nextDraw"
+                        ],
+                        "type": "string",
+                        "description": "Сортировка; минус — по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (до 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы (только для того же sort)",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.GamesV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Апстрим не ответил вовремя",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/games/{name}": {
+            "get": {
+                "description": "Лотерея с текущим и последним проведённым тиражом",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Лотерея",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.GameV1"
+                        }
+                    },
+                    "404": {
+                        "description": "Игра не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/games/{name}/draws/latest": {
+            "get": {
+                "description": "Последний тираж игры, по которому известны результаты",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Последний проведённый тираж",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DrawV1"
+                        }
+                    },
+                    "404": {
+                        "description": "Игра не найдена или ещё не проводилась",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/games/{name}/draws/{number}": {
+            "get": {
+                "description": "Тираж по названию игры и номеру",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Тираж",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название игры (например: 5x36plus, 6x45)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер тиража",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DrawV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный номер тиража",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тираж не найден",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/momental": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Моментальные лотереи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "OS",
+                        "description": "Платформа витрины CMS",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ALL",
+                        "description": "Сегмент пользователей витрины CMS",
+                        "name": "userSegment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MomentCardsV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/momental/cards": {
+            "get": {
+                "description": "Карточки моментальных лотерей с поиском по названию, фильтрами по цене билета и максимальному выигрышу и сортировкой. Суммы в рублях, границы включаются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Поиск моментальных лотерей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Подстрока названия, без учёта регистра",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Доступна для покупки",
+                        "name": "available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Цена билета от",
+                        "name": "priceFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Цена билета до",
+                        "name": "priceTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальный выигрыш от",
+                        "name": "prizeFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальный выигрыш до",
+                        "name": "prizeTo",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "price",
+                            "-price",
+                            "maxPrize",
+                            "-maxPrize"
+                        ],
+                        "type": "string",
+                        "description": "Сортировка; минус — по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "OS",
+                        "description": "Платформа витрины CMS",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ALL",
+                        "description": "Сегмент пользователей витрины CMS",
+                        "name": "userSegment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MomentCardsV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/momental/cards/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Моментальная лотерея",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор карточки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "OS",
+                        "description": "Платформа витрины CMS",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ALL",
+                        "description": "Сегмент пользователей витрины CMS",
+                        "name": "userSegment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MomentCardV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Карточка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка апстрима",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/momental/changes": {
+            "get": {
+                "description": "Добавленные, удалённые и изменившие цену или максимальный выигрыш карточки по сравнению снимков витрины. Без since — весь хранимый журнал.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Изменения витрины моментальных лотерей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Только изменения позже этого момента (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MomentalChangesV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Отслеживание отключено",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/graphql": {
+            "get": {
+                "description": "Игры, тиражи с историей и моментальные лотереи одним запросом. Схема доступна через интроспекцию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Запрос (для GET)",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Запрос (для POST)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.graphqlRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "data и errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Игры, тиражи с историей и моментальные лотереи одним запросом. Схема доступна через интроспекцию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Запрос (для GET)",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Запрос (для POST)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.graphqlRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "data и errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Метрики в текстовом формате Prometheus",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "service"
+                ],
+                "summary": "Метрики прокси",
+                "responses": {
+                    "200": {
+                        "description": "Метрики",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "main.APIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ttl": {
+                    "description": "TTL в формате Go duration, например \"720h\"; пусто — бессрочный ключ",
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "$ref": "#/definitions/main.APIKey"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.DrawV1": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "game": {
+                    "type": "string",
+                    "example": "6x45"
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 1234
+                },
+                "numbers": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "prizes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.PrizeCategoryV1"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "completed"
+                    ],
+                    "example": "completed"
+                },
+                "superPrize": {
+                    "type": "integer",
+                    "example": 50000000
+                },
+                "totalPrize": {
+                    "type": "integer"
+                },
+                "totalWinners": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.GameV1": {
+            "type": "object",
+            "properties": {
+                "currentDraw": {
+                    "description": "CurrentDraw — тираж, на который идут продажи",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.DrawV1"
+                        }
+                    ]
+                },
+                "lastDraw": {
+                    "description": "LastDraw — последний проведённый тираж",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.DrawV1"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "6x45"
+                },
+                "salesOpen": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Гослото «6 из 45»"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "draw",
+                        "bingo",
+                        "instant"
+                    ],
+                    "example": "draw"
+                }
+            }
+        },
+        "main.GamesV1": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.GameV1"
+                    }
+                },
+                "nextCursor": {
+                    "description": "NextCursor — значение cursor для следующей страницы; пусто на последней",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total — сколько лотерей подходит под фильтры, без учёта страницы",
+                    "type": "integer"
+                }
+            }
+        },
+        "main.MomentCardImagesV1": {
+            "type": "object",
+            "properties": {
+                "large": {
+                    "type": "string"
+                },
+                "small": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.MomentCardV1": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean"
+                },
+                "badge": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "description": "ImageURL — большая картинка; все размеры в Images",
+                    "type": "string"
+                },
+                "images": {
+                    "$ref": "#/definitions/main.MomentCardImagesV1"
+                },
+                "maxPrize": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.MomentCardsV1": {
+            "type": "object",
+            "properties": {
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.MomentCardV1"
+                    }
+                },
+                "total": {
+                    "description": "Total — сколько карточек подходит под фильтры; у /momental не заполняется",
+                    "type": "integer"
+                }
+            }
+        },
+        "main.MomentalChangeV1": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "description": "Card — карточка после изменения; у удалённой — последнее известное состояние",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.MomentCardV1"
+                        }
+                    ]
+                },
+                "fields": {
+                    "description": "Fields — изменившиеся поля у changed: price, maxPrize",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "previous": {
+                    "description": "Previous — карточка до изменения, только у changed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.MomentCardV1"
+                        }
+                    ]
+                },
+                "time": {
+                    "description": "Time — когда изменение замечено (время снимка)",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "added",
+                        "removed",
+                        "changed"
+                    ]
+                }
+            }
+        },
+        "main.MomentalChangesV1": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.MomentalChangeV1"
+                    }
+                },
+                "snapshotAt": {
+                    "description": "SnapshotAt — время последнего снимка; изменения после него ещё не известны",
+                    "type": "string"
+                }
+            }
+        },
+        "main.PrizeCategoryV1": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "matched": {
+                    "description": "Matched — сколько чисел нужно угадать, например \"6\" или \"5+1\"",
+                    "type": "string",
+                    "example": "6"
+                },
+                "winners": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.graphqlRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API-ключ; можно также передать параметром запроса api_key",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -235,7 +2233,7 @@ var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
 	BasePath:         "/",
-	Schemes:          []string{},
+	Schemes:          []string{"http", "https"},
 	Title:            "Stoloto API Proxy",
 	Description:      "Прокси-сервер для API Stoloto",
 	InfoInstanceName: "swagger",
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// DrawEventsConfig — опрос списка игр, по которому замечаются новые тиражи.
+// Опрос идёт через кеш (staleWhileRevalidate), поэтому частота запросов
+// к Stoloto от него не зависит. Нулевой или отрицательный pollInterval
+// отключает события.
+type DrawEventsConfig struct {
+	PollInterval Duration `json:"pollInterval"`
+}
+
+type drawEventType int
+
+const (
+	// drawOpened — открыты продажи на новый тираж
+	drawOpened drawEventType = iota + 1
+	// drawCompleted — тираж проведён, известны результаты
+	drawCompleted
+)
+
+func (t drawEventType) String() string {
+	if t == drawOpened {
+		return "opened"
+	}
+	return "completed"
+}
+
+type drawEvent struct {
+	Type drawEventType
+	Game string
+	Draw *upstreamDraw
+}
+
+var drawEventsTotal = newCounter("stoloto_draw_events_total", "Draw events detected by polling the games list")
+
+// eventHub рассылает события подписчикам. Подписчик, который не успевает
+// читать, теряет события, а не задерживает остальных. Нулевое значение готово
+// к работе.
+type eventHub[T any] struct {
+	mu   sync.Mutex
+	subs map[chan T]struct{}
+}
+
+// subscribe возвращает канал событий и функцию отписки. Канал закрывается
+// отпиской или closeAll.
+func (h *eventHub[T]) subscribe() (<-chan T, func()) {
+	ch := make(chan T, 64)
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = map[chan T]struct{}{}
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *eventHub[T]) publish(ev T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (h *eventHub[T]) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// drawWatcher рассылает события по тиражам подписчикам. Опрос запускается
+// при первой подписке и живёт до остановки сервера.
+type drawWatcher struct {
+	eventHub[drawEvent]
+	once sync.Once
+}
+
+var drawEvents = &drawWatcher{}
+
+func (w *drawWatcher) enabled() bool {
+	return cfg.DrawEvents.PollInterval > 0
+}
+
+// subscribe подписывает на события тиражей; канал закрывается отпиской или
+// остановкой сервера
+func (w *drawWatcher) subscribe() (<-chan drawEvent, func()) {
+	w.once.Do(func() { background.Go("draw-events", w.run) })
+	return w.eventHub.subscribe()
+}
+
+func (w *drawWatcher) publish(ev drawEvent) {
+	drawEventsTotal.add(labels("type", ev.Type.String()), 1)
+	w.eventHub.publish(ev)
+}
+
+// drawNumbers — номера текущего и последнего проведённого тиража игры
+type drawNumbers struct {
+	current, completed int
+}
+
+func (w *drawWatcher) run(ctx context.Context) {
+	defer w.closeAll()
+	ctx = withPriority(ctx, priorityBackground)
+
+	ticker := time.NewTicker(time.Duration(cfg.DrawEvents.PollInterval))
+	defer ticker.Stop()
+
+	var seen map[string]drawNumbers
+	for {
+		games, _, err := fetchGames(ctx)
+		if err != nil {
+			log.Printf("Draw events: %v", err)
+		} else {
+			seen = w.detect(ctx, seen, games)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// detect сравнивает список игр с предыдущим опросом и публикует события.
+// Первый опрос только запоминает состояние.
+func (w *drawWatcher) detect(ctx context.Context, seen map[string]drawNumbers, games *upstreamGamesResponse) map[string]drawNumbers {
+	next := make(map[string]drawNumbers, len(games.Games))
+	for _, g := range games.Games {
+		var n drawNumbers
+		if g.Draw != nil {
+			n.current = g.Draw.Number
+		}
+		if g.CompletedDraw != nil {
+			n.completed = g.CompletedDraw.Number
+		}
+		next[g.Name] = n
+
+		prev, ok := seen[g.Name]
+		if seen == nil || !ok {
+			continue
+		}
+		if n.completed > prev.completed {
+			// В списке игр у тиража нет выигрышных категорий
+			draw, _, err := fetchDraw(ctx, g.Name, n.completed)
+			if err != nil {
+				draw = g.CompletedDraw
+			}
+			w.publish(drawEvent{Type: drawCompleted, Game: g.Name, Draw: draw})
+		}
+		if n.current > prev.current {
+			w.publish(drawEvent{Type: drawOpened, Game: g.Name, Draw: g.Draw})
+		}
+	}
+	return next
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Выгрузка истории тиражей одной игры плоским файлом: строка на тираж,
+// каждый шар и каждая выигрышная категория в своих колонках. Тиражи
+// запрашиваются по порядку с небольшим упреждением и пишутся в ответ
+// по мере получения, так что в памяти держится только окно упреждения.
+
+const (
+	// maxExportDraws ограничивает диапазон одной выгрузки
+	maxExportDraws = 5000
+	// defaultExportDraws — сколько последних тиражей выгружается без from
+	defaultExportDraws = 100
+	// exportPrefetch — сколько тиражей запрашивается впереди записываемого
+	exportPrefetch = 8
+)
+
+// exportLayout — число колонок под шары и категории. Определяется по первому
+// тиражу диапазона; у тиражей с другим составом лишнее отбрасывается,
+// недостающее остаётся пустым.
+type exportLayout struct {
+	balls      int
+	categories int
+}
+
+// drawRowWriter пишет выгрузку в конкретном формате
+type drawRowWriter interface {
+	writeHeader(l exportLayout) error
+	writeRow(d DrawV1) error
+	close() error
+}
+
+var exportFormats = map[string]struct {
+	contentType string
+	newWriter   func(w io.Writer) drawRowWriter
+}{
+	"csv":     {"text/csv; charset=utf-8", func(w io.Writer) drawRowWriter { return &csvDrawWriter{w: csv.NewWriter(w)} }},
+	"parquet": {"application/vnd.apache.parquet", func(w io.Writer) drawRowWriter { return &parquetDrawWriter{out: w} }},
+}
+
+// ExportDraws godoc
+// @Summary Выгрузка истории тиражей
+// @Description Тиражи игры из диапазона номеров в CSV или Parquet: дата, номер, шары, суперприз, победители и выигрыши по категориям. Без from — последние 100 тиражей, без to — до последнего проведённого.
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.apache.parquet
+// @Security ApiKeyAuth
+// @Param name query string true "Название игры (например: 5x36plus, 6x45)"
+// @Param from query int false "Первый тираж"
+// @Param to query int false "Последний тираж"
+// @Param format query string false "Формат" Enums(csv, parquet) default(csv)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 404 {object} ErrorResponse "Игра не найдена"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/export [get]
+func handleExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("name")
+	if name == "" {
+		sendError(w, `Missing required parameter: "name"`, http.StatusBadRequest)
+		return
+	}
+
+	formatName := query.Get("format")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, ok := exportFormats[formatName]
+	if !ok {
+		sendError(w, "Unsupported format: "+formatName, http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := exportRange(r.Context(), name, query.Get("from"), query.Get("to"))
+	if err != nil {
+		if errors.Is(err, errInvalidExportRange) {
+			sendError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			sendAPIError(w, err)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithCancel(withPriority(r.Context(), priorityBulk))
+	draws := prefetchDraws(ctx, name, from, to, exportPrefetch)
+	defer stopPrefetch(cancel, draws)
+
+	// Заголовки ответа уходят только после первого тиража: до этого ошибку
+	// ещё можно вернуть статусом
+	first, err := nextDraw(draws)
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+	if first == nil {
+		sendError(w, fmt.Sprintf("No draws found for game '%s' in range %d-%d", name, from, to), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_%d-%d.%s"`, name, from, to, formatName))
+	w.Header().Set("Cache-Control", "no-store")
+
+	rc := http.NewResponseController(w)
+	out := format.newWriter(w)
+	d := toDrawV1(name, first)
+	if err := out.writeHeader(exportLayout{balls: len(d.Numbers), categories: len(d.Prizes)}); err != nil {
+		log.Printf("Export %s: %v", name, err)
+		return
+	}
+
+	for draw := first; draw != nil; draw, err = nextDraw(draws) {
+		// Выгрузка может идти дольше WriteTimeout сервера: срок продлевается
+		// на каждую строку. Нулевой writeTimeout, как у http.Server, — без срока
+		var deadline time.Time
+		if timeout := time.Duration(cfg.Server.WriteTimeout); timeout > 0 {
+			deadline = time.Now().Add(timeout)
+		}
+		rc.SetWriteDeadline(deadline)
+		if err := out.writeRow(toDrawV1(name, draw)); err != nil {
+			log.Printf("Export %s: %v", name, err)
+			return
+		}
+	}
+	// Ответ уже начат: соединение обрывается, чтобы клиент не принял
+	// недописанный файл за целый
+	if err != nil {
+		log.Printf("Export %s aborted: %v", name, err)
+		panic(http.ErrAbortHandler)
+	}
+	if err := out.close(); err != nil {
+		log.Printf("Export %s: %v", name, err)
+	}
+}
+
+var errInvalidExportRange = errors.New("invalid range")
+
+// exportRange разбирает from и to; без to берётся последний проведённый тираж
+func exportRange(ctx context.Context, name, fromParam, toParam string) (int, int, error) {
+	var from, to int
+	var err error
+	if toParam != "" {
+		if to, err = strconv.Atoi(toParam); err != nil || to <= 0 {
+			return 0, 0, fmt.Errorf("%w: to must be a positive draw number", errInvalidExportRange)
+		}
+	} else {
+		game, _, _, err := findGame(ctx, name)
+		if err != nil {
+			return 0, 0, err
+		}
+		if game.CompletedDraw == nil {
+			return 0, 0, fmt.Errorf("%w: no completed draws for '%s'", errDrawNotFound, name)
+		}
+		to = game.CompletedDraw.Number
+	}
+
+	if fromParam != "" {
+		if from, err = strconv.Atoi(fromParam); err != nil || from <= 0 {
+			return 0, 0, fmt.Errorf("%w: from must be a positive draw number", errInvalidExportRange)
+		}
+	} else {
+		from = max(1, to-defaultExportDraws+1)
+	}
+
+	if from > to {
+		return 0, 0, fmt.Errorf("%w: from is greater than to", errInvalidExportRange)
+	}
+	if to-from+1 > maxExportDraws {
+		return 0, 0, fmt.Errorf("%w: at most %d draws per export", errInvalidExportRange, maxExportDraws)
+	}
+	return from, to, nil
+}
+
+type drawResult struct {
+	draw *upstreamDraw
+	err  error
+}
+
+// prefetchDraws запрашивает тиражи от from до to, опережая чтение не больше
+// чем на depth тиражей; результаты приходят в том же порядке. При from > to
+// тиражи идут от новых к старым.
+func prefetchDraws(ctx context.Context, name string, from, to, depth int) <-chan chan drawResult {
+	step := 1
+	if from > to {
+		step = -1
+	}
+
+	results := make(chan chan drawResult, depth)
+	go func() {
+		// results закрывается только после всех запросов, см. stopPrefetch
+		var fetches sync.WaitGroup
+		defer close(results)
+		defer fetches.Wait()
+		for n := from; n != to+step; n += step {
+			ch := make(chan drawResult, 1)
+			select {
+			case results <- ch:
+			case <-ctx.Done():
+				return
+			}
+			fetches.Add(1)
+			go func() {
+				defer fetches.Done()
+				draw, _, err := fetchDraw(ctx, name, n)
+				ch <- drawResult{draw, err}
+			}()
+		}
+	}()
+	return results
+}
+
+// stopPrefetch отменяет опережающие запросы и ждёт их завершения, чтобы после
+// выхода из обработчика к апстриму не оставалось обращений
+func stopPrefetch(cancel context.CancelFunc, results ...<-chan chan drawResult) {
+	cancel()
+	for _, r := range results {
+		for range r {
+		}
+	}
+}
+
+// nextDraw возвращает следующий существующий тираж или nil в конце диапазона.
+// Пропускаются только отсутствующие тиражи; сбой апстрима прерывает выгрузку.
+func nextDraw(results <-chan chan drawResult) (*upstreamDraw, error) {
+	for ch := range results {
+		res := <-ch
+		if errors.Is(res.err, errDrawNotFound) {
+			continue
+		}
+		return res.draw, res.err
+	}
+	return nil, nil
+}
+
+// CSV
+
+type csvDrawWriter struct {
+	w      *csv.Writer
+	layout exportLayout
+}
+
+func (c *csvDrawWriter) writeHeader(l exportLayout) error {
+	c.layout = l
+	header := []string{"date", "number"}
+	for i := 1; i <= l.balls; i++ {
+		header = append(header, fmt.Sprintf("ball_%d", i))
+	}
+	header = append(header, "super_prize")
+	for i := 1; i <= l.categories; i++ {
+		header = append(header, fmt.Sprintf("category_%d_winners", i), fmt.Sprintf("category_%d_prize", i))
+	}
+	header = append(header, "total_winners", "total_prize")
+	return c.w.Write(header)
+}
+
+func (c *csvDrawWriter) writeRow(d DrawV1) error {
+	row := []string{d.Date.Format(time.RFC3339), strconv.Itoa(d.Number)}
+	for i := range c.layout.balls {
+		if i < len(d.Numbers) {
+			row = append(row, strconv.Itoa(d.Numbers[i]))
+		} else {
+			row = append(row, "")
+		}
+	}
+	row = append(row, strconv.FormatInt(d.SuperPrize, 10))
+	for i := range c.layout.categories {
+		if i < len(d.Prizes) {
+			row = append(row, strconv.Itoa(d.Prizes[i].Winners), strconv.FormatInt(d.Prizes[i].Amount, 10))
+		} else {
+			row = append(row, "", "")
+		}
+	}
+	row = append(row, strconv.Itoa(d.TotalWinners), strconv.FormatInt(d.TotalPrize, 10))
+
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvDrawWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Parquet
+
+// parquetRowGroupSize — размер группы строк. Писатель держит группу в памяти
+// до сброса, поэтому она маленькая: выгрузка не должна копиться целиком.
+const parquetRowGroupSize = 1 << 20
+
+type parquetDrawWriter struct {
+	out    io.Writer
+	pw     *writer.CSVWriter
+	layout exportLayout
+}
+
+func (p *parquetDrawWriter) writeHeader(l exportLayout) error {
+	p.layout = l
+	md := []string{
+		"name=date, type=INT64, convertedtype=TIMESTAMP_MILLIS",
+		"name=number, type=INT32",
+	}
+	for i := 1; i <= l.balls; i++ {
+		md = append(md, fmt.Sprintf("name=ball_%d, type=INT32, repetitiontype=OPTIONAL", i))
+	}
+	md = append(md, "name=super_prize, type=INT64")
+	for i := 1; i <= l.categories; i++ {
+		md = append(md,
+			fmt.Sprintf("name=category_%d_winners, type=INT32, repetitiontype=OPTIONAL", i),
+			fmt.Sprintf("name=category_%d_prize, type=INT64, repetitiontype=OPTIONAL", i),
+		)
+	}
+	md = append(md, "name=total_winners, type=INT32", "name=total_prize, type=INT64")
+
+	pw, err := writer.NewCSVWriterFromWriter(md, p.out, 1)
+	if err != nil {
+		return err
+	}
+	pw.RowGroupSize = parquetRowGroupSize
+	p.pw = pw
+	return nil
+}
+
+func (p *parquetDrawWriter) writeRow(d DrawV1) error {
+	row := []any{d.Date.UnixMilli(), int32(d.Number)}
+	for i := range p.layout.balls {
+		if i < len(d.Numbers) {
+			row = append(row, int32(d.Numbers[i]))
+		} else {
+			row = append(row, nil)
+		}
+	}
+	row = append(row, d.SuperPrize)
+	for i := range p.layout.categories {
+		if i < len(d.Prizes) {
+			row = append(row, int32(d.Prizes[i].Winners), d.Prizes[i].Amount)
+		} else {
+			row = append(row, nil, nil)
+		}
+	}
+	row = append(row, int32(d.TotalWinners), d.TotalPrize)
+	return p.pw.Write(row)
+}
+
+func (p *parquetDrawWriter) close() error {
+	return p.pw.WriteStop()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"DOUPIG/fakestoloto"
+)
+
+func TestExportSkipsMissingDraws(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+	current := fake.CurrentDraw("6x45")
+
+	// Тиражи после текущего ещё не существуют и пропускаются
+	resp, err := http.Get(fmt.Sprintf("%s/api/export?name=6x45&from=%d&to=%d", proxy.URL, current-3, current+5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1+4 {
+		t.Fatalf("rows = %d, want header and 4 draws", len(rows))
+	}
+}
+
+func TestExportWithoutWriteTimeout(t *testing.T) {
+	// writeTimeout 0 — без ограничения, как у http.Server
+	fake, proxy := startProxy(t, func(c *Config) { c.Server.WriteTimeout = 0 })
+	last := fake.CurrentDraw("6x45") - 1
+
+	resp, err := http.Get(fmt.Sprintf("%s/api/export?name=6x45&from=%d&to=%d", proxy.URL, last-2, last))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1+3 {
+		t.Fatalf("rows = %d, want header and 3 draws", len(rows))
+	}
+}
+
+func TestExportSurfacesUpstreamErrors(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+	last := fake.CurrentDraw("6x45") - 1
+	url := fmt.Sprintf("%s/api/export?name=6x45&from=%d&to=%d", proxy.URL, last-9, last)
+
+	// Ошибка в ответе 200 на первый тираж — не пропуск, а сбой апстрима
+	fake.InjectFault(fakestoloto.Fault{Path: fmt.Sprintf("/service/draws/6x45/%d", last-9), Status: http.StatusOK})
+	if resp := getJSON(t, url, nil); resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502", resp.StatusCode)
+	}
+	fake.ClearFaults()
+
+	// Сбой посередине обрывает соединение, а не отдаёт файл без части тиражей.
+	// Небольшой ответ может не успеть уйти клиенту, и тогда ошибку вернёт сам Get.
+	fake.InjectFault(fakestoloto.Fault{Path: fmt.Sprintf("/service/draws/6x45/%d", last-4), Status: http.StatusOK})
+	resp, err := http.Get(url)
+	if err == nil {
+		_, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	if err == nil {
+		t.Fatal("export with a failed draw in the middle was delivered as complete")
+	}
+}
@@ -0,0 +1,330 @@
+// Package fakestoloto — фейковый апстрим Stoloto для локальной разработки и тестов.
+//
+// Реализует эндпоинты, к которым обращается прокси:
+//
+//	/p/api/mobile/api/v35/service/games/info-new
+//	/p/api/mobile/api/v35/service/draws/{name}/{number}
+//	/cms/api/moment-cards-section
+//
+// Данные тиражей генерируются детерминированно по имени игры и номеру,
+// поэтому любая часть истории доступна без фикстур на диске. Сценарии
+// (завершение тиража, ошибки, задержки, битый JSON) задаются методами Fake.
+package fakestoloto
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIPrefix — префикс мобильного API; BaseURL прокси должен оканчиваться им
+const APIPrefix = "/p/api/mobile/api/v35"
+
+// MomentalPath — путь CMS-эндпоинта моментальных лотерей
+const MomentalPath = "/cms/api/moment-cards-section"
+
+// Fault — сбой, который фейк вернёт на запросы с путём, начинающимся с Path
+type Fault struct {
+	// Path — префикс пути без APIPrefix, например "/service/draws/"; пусто — все пути
+	Path string
+	// Status — код ответа; 0 — ответить как обычно (полезно вместе с Delay)
+	Status int
+	// Delay — задержка перед ответом
+	Delay time.Duration
+	// Malformed — вернуть обрезанный JSON с кодом 200
+	Malformed bool
+	// Times — сколько запросов затронет сбой; 0 — пока не вызван ClearFaults
+	Times int
+}
+
+// Fake — http.Handler, имитирующий Stoloto
+type Fake struct {
+	mu       sync.Mutex
+	games    []*gameSpec
+	cards    []cardSpec
+	faults   []*Fault
+	latency  time.Duration
+	requests []string
+	// RequireToken — отвечать 401 на запросы без заголовка Gosloto-Partner
+	RequireToken bool
+}
+
+// New создаёт фейк с набором игр, чьи ближайшие тиражи отсчитываются от now
+func New(now time.Time) *Fake {
+	return &Fake{
+		games:        defaultGames(now),
+		cards:        defaultCards(),
+		RequireToken: true,
+	}
+}
+
+// Server — фейк, запущенный на httptest.Server
+type Server struct {
+	*Fake
+	*httptest.Server
+}
+
+// NewServer запускает фейк на случайном локальном порту. Не забудьте вызвать Close.
+func NewServer() *Server {
+	f := New(time.Now())
+	return &Server{Fake: f, Server: httptest.NewServer(f)}
+}
+
+// BaseURL — значение для upstream.baseURL прокси
+func (s *Server) BaseURL() string {
+	return s.URL + APIPrefix
+}
+
+// MomentalURL — значение для upstream.momentalURL прокси
+func (s *Server) MomentalURL() string {
+	return s.URL + MomentalPath + "?platform=OS&user-segment=ALL"
+}
+
+// CompleteDraw завершает текущий тираж игры и открывает продажи на следующий
+func (f *Fake) CompleteDraw(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	g := f.game(name)
+	if g == nil || g.Kind == "instant" {
+		return fmt.Errorf("fakestoloto: no draw game %q", name)
+	}
+
+	g.Current++
+	g.Next = g.Next.Add(g.Interval)
+	return nil
+}
+
+// CompleteAll завершает текущие тиражи всех тиражных игр
+func (f *Fake) CompleteAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, g := range f.games {
+		if g.Kind != "instant" {
+			g.Current++
+			g.Next = g.Next.Add(g.Interval)
+		}
+	}
+}
+
+// SetSuperPrize меняет суперприз текущего тиража
+func (f *Fake) SetSuperPrize(name string, amount int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	g := f.game(name)
+	if g == nil {
+		return fmt.Errorf("fakestoloto: no game %q", name)
+	}
+	g.SuperPrize = amount
+	return nil
+}
+
+// CurrentDraw возвращает номер тиража, на который идут продажи
+func (f *Fake) CurrentDraw(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if g := f.game(name); g != nil {
+		return g.Current
+	}
+	return 0
+}
+
+// InjectFault добавляет сбой; сбои проверяются в порядке добавления
+func (f *Fake) InjectFault(fault Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = append(f.faults, &fault)
+}
+
+// ClearFaults удаляет все сбои
+func (f *Fake) ClearFaults() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = nil
+}
+
+// SetLatency задаёт задержку для всех ответов
+func (f *Fake) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// Requests возвращает пути всех полученных запросов
+func (f *Fake) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func (f *Fake) game(name string) *gameSpec {
+	for _, g := range f.games {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// takeFault возвращает первый подходящий сбой, уменьшая его счётчик
+func (f *Fake) takeFault(path string) *Fault {
+	for i, fault := range f.faults {
+		if !strings.HasPrefix(path, fault.Path) {
+			continue
+		}
+
+		taken := *fault
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				f.faults = append(f.faults[:i], f.faults[i+1:]...)
+			}
+		}
+		return &taken
+	}
+	return nil
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, APIPrefix)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, r.URL.RequestURI())
+	fault := f.takeFault(path)
+	latency := f.latency
+	f.mu.Unlock()
+
+	if fault != nil {
+		latency += fault.Delay
+	}
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil && fault.Status != 0 {
+		writeJSON(w, fault.Status, map[string]any{
+			"requestStatus": "error",
+			"errors":        []map[string]any{{"code": "INTERNAL_ERROR", "message": http.StatusText(fault.Status)}},
+		})
+		return
+	}
+	if fault != nil && fault.Malformed {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"requestStatus":"success","games":[{"name":"6x4`))
+		return
+	}
+
+	if f.RequireToken && r.Header.Get("Gosloto-Partner") == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{
+			"requestStatus": "error",
+			"errors":        []map[string]any{{"code": "PARTNER_REQUIRED", "message": "Gosloto-Partner header is required"}},
+		})
+		return
+	}
+
+	switch {
+	case r.Method != http.MethodGet && r.Method != http.MethodHead:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	case path == "/service/games/info-new":
+		f.serveGames(w)
+	case strings.HasPrefix(path, "/service/draws/"):
+		f.serveDraw(w, strings.TrimPrefix(path, "/service/draws/"))
+	case r.URL.Path == MomentalPath:
+		f.serveMomental(w, r)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]any{"requestStatus": "error", "errors": []map[string]any{{"code": "NOT_FOUND"}}})
+	}
+}
+
+func (f *Fake) serveGames(w http.ResponseWriter) {
+	f.mu.Lock()
+	games := make([]map[string]any, 0, len(f.games))
+	for _, g := range f.games {
+		game := map[string]any{
+			"name":      g.Name,
+			"title":     g.Title,
+			"type":      g.Kind,
+			"salesOpen": g.Kind != "instant",
+		}
+		if g.Kind != "instant" {
+			game["draw"] = g.activeDraw()
+			game["completedDraw"] = g.completedDraw(g.Current - 1)
+		}
+		games = append(games, game)
+	}
+	f.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"requestStatus": "success",
+		"errors":        []any{},
+		"games":         games,
+	})
+}
+
+func (f *Fake) serveDraw(w http.ResponseWriter, rest string) {
+	name, numStr, _ := strings.Cut(rest, "/")
+	number, err := strconv.Atoi(numStr)
+
+	f.mu.Lock()
+	g := f.game(name)
+	var draw map[string]any
+	switch {
+	case g == nil || g.Kind == "instant" || err != nil || number < 1:
+	case number == g.Current:
+		draw = g.activeDraw()
+	case number < g.Current:
+		draw = g.completedDraw(number)
+	}
+	f.mu.Unlock()
+
+	if draw == nil {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"requestStatus": "error",
+			"errors":        []map[string]any{{"code": "DRAW_NOT_FOUND", "message": "Тираж не найден"}},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"requestStatus": "success",
+		"errors":        []any{},
+		"draw":          draw,
+	})
+}
+
+func (f *Fake) serveMomental(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	cards := make([]map[string]any, 0, len(f.cards))
+	for _, c := range f.cards {
+		cards = append(cards, c.json())
+	}
+	f.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status": "success",
+		"data": map[string]any{
+			"section":     "moment-cards",
+			"platform":    r.URL.Query().Get("platform"),
+			"userSegment": r.URL.Query().Get("user-segment"),
+			"cards":       cards,
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
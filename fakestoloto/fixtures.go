@@ -0,0 +1,172 @@
+package fakestoloto
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// gameSpec описывает игру фейка: правила выбора шаров и расписание тиражей
+type gameSpec struct {
+	Name     string
+	Title    string
+	Kind     string // draw, bingo или instant
+	Pick     int    // сколько шаров выпадает
+	Of       int    // из скольких
+	Interval time.Duration
+	// Current — номер тиража, на который идут продажи; Current-1 — последний завершённый
+	Current    int
+	SuperPrize int64
+	// Next — время ближайшего тиража
+	Next time.Time
+}
+
+// msk — тиражи Stoloto проводятся по московскому времени
+var msk = time.FixedZone("MSK", 3*60*60)
+
+func defaultGames(now time.Time) []*gameSpec {
+	now = now.In(msk)
+	next := func(interval time.Duration) time.Time {
+		return now.Truncate(interval).Add(interval)
+	}
+
+	return []*gameSpec{
+		{Name: "6x45", Title: "Гослото «6 из 45»", Kind: "draw", Pick: 6, Of: 45, Interval: 3 * time.Hour, Current: 1710, SuperPrize: 168_356_112, Next: next(3 * time.Hour)},
+		{Name: "5x36plus", Title: "Гослото «5 из 36»", Kind: "draw", Pick: 5, Of: 36, Interval: 15 * time.Minute, Current: 251433, SuperPrize: 4_105_226, Next: next(15 * time.Minute)},
+		{Name: "7x49", Title: "Гослото «7 из 49»", Kind: "draw", Pick: 7, Of: 49, Interval: 24 * time.Hour, Current: 781, SuperPrize: 96_003_500, Next: next(24 * time.Hour)},
+		{Name: "4x20", Title: "Гослото «4 из 20»", Kind: "draw", Pick: 4, Of: 20, Interval: time.Hour, Current: 12230, SuperPrize: 380_447_025, Next: next(time.Hour)},
+		{Name: "ruslotto", Title: "Русское лото", Kind: "bingo", Pick: 64, Of: 90, Interval: 7 * 24 * time.Hour, Current: 1612, SuperPrize: 500_000_000, Next: next(7 * 24 * time.Hour)},
+		{Name: "housing", Title: "Жилищная лотерея", Kind: "bingo", Pick: 61, Of: 90, Interval: 7 * 24 * time.Hour, Current: 660, SuperPrize: 300_000_000, Next: next(7 * 24 * time.Hour)},
+		{Name: "momental", Title: "Моментальные лотереи", Kind: "instant"},
+	}
+}
+
+// seeded возвращает генератор, детерминированный для пары игра+тираж,
+// чтобы один и тот же тираж всегда возвращал одинаковые данные
+func seeded(name string, number int) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(number))
+	h.Write(buf[:])
+	sum := h.Sum64()
+	return rand.New(rand.NewPCG(sum, sum>>1|1))
+}
+
+// drawDate — время тиража number относительно ближайшего
+func (g *gameSpec) drawDate(number int) time.Time {
+	return g.Next.Add(time.Duration(number-g.Current) * g.Interval)
+}
+
+// activeDraw — тираж, на который идут продажи
+func (g *gameSpec) activeDraw() map[string]any {
+	return map[string]any{
+		"id":         g.drawID(g.Current),
+		"number":     g.Current,
+		"date":       g.drawDate(g.Current).Format(time.RFC3339),
+		"status":     "SALES",
+		"superPrize": g.SuperPrize,
+	}
+}
+
+// completedDraw генерирует завершённый тираж с комбинацией и выигрышными категориями
+func (g *gameSpec) completedDraw(number int) map[string]any {
+	r := seeded(g.Name, number)
+
+	balls := r.Perm(g.Of)[:g.Pick]
+	if g.Kind != "bingo" {
+		slices.Sort(balls)
+	}
+	serialized := make([]string, len(balls))
+	for i, b := range balls {
+		serialized[i] = strconv.Itoa(b + 1)
+	}
+
+	// Чем меньше угадано, тем больше победителей и меньше выигрыш
+	categories := make([]map[string]any, 0)
+	totalWinners, totalPrize := 0, int64(0)
+	superPrize := max(g.SuperPrize-int64(g.Current-number)*int64(r.IntN(500_000)), 1_000_000)
+	jackpotWon := r.IntN(20) == 0
+
+	for i := range min(g.Pick-1, 5) {
+		guessed := g.Pick - i
+		participants := r.IntN(10*(i+1)*(i+1)*(i+1)) + i
+		amount := int64(0)
+		switch {
+		case i == 0 && jackpotWon:
+			participants, amount = 1, superPrize
+		case i == 0:
+			participants = 0
+		default:
+			amount = int64(1_000_000 / (i * i * 10) * 10)
+		}
+
+		categories = append(categories, map[string]any{
+			"number":       i + 1,
+			"combination":  strconv.Itoa(guessed),
+			"participants": participants,
+			"amount":       amount,
+		})
+		totalWinners += participants
+		totalPrize += int64(participants) * amount
+	}
+
+	return map[string]any{
+		"id":                g.drawID(number),
+		"number":            number,
+		"date":              g.drawDate(number).Format(time.RFC3339),
+		"status":            "COMPLETED",
+		"superPrize":        superPrize,
+		"combination":       map[string]any{"serialized": serialized},
+		"winningCategories": categories,
+		"totalWinners":      totalWinners,
+		"totalPrize":        totalPrize,
+	}
+}
+
+func (g *gameSpec) drawID(number int) int64 {
+	h := fnv.New32a()
+	h.Write([]byte(g.Name))
+	return int64(h.Sum32()%1000)*10_000_000 + int64(number)
+}
+
+type cardSpec struct {
+	ID        string
+	Name      string
+	Price     int
+	MaxPrize  int64
+	Available bool
+	Badge     string
+}
+
+func defaultCards() []cardSpec {
+	return []cardSpec{
+		{ID: "1001", Name: "Золотая подкова", Price: 100, MaxPrize: 1_000_000, Available: true},
+		{ID: "1002", Name: "Бинго-вертушка", Price: 50, MaxPrize: 250_000, Available: true},
+		{ID: "1003", Name: "Мега-удача", Price: 500, MaxPrize: 10_000_000, Available: true, Badge: "Хит"},
+		{ID: "1004", Name: "Морской бой", Price: 150, MaxPrize: 1_500_000, Available: true},
+		{ID: "1005", Name: "Кроссворд", Price: 200, MaxPrize: 2_000_000, Available: false},
+		{ID: "1006", Name: "Сундук сокровищ", Price: 300, MaxPrize: 3_000_000, Available: true, Badge: "Новинка"},
+	}
+}
+
+func (c cardSpec) json() map[string]any {
+	card := map[string]any{
+		"id":        c.ID,
+		"name":      c.Name,
+		"price":     c.Price,
+		"maxPrize":  c.MaxPrize,
+		"available": c.Available,
+		"images": map[string]any{
+			"small": "https://cdn.stoloto.ru/moment/" + c.ID + "_small.png",
+			"large": "https://cdn.stoloto.ru/moment/" + c.ID + "_large.png",
+		},
+	}
+	if c.Badge != "" {
+		card["badge"] = c.Badge
+	}
+	return card
+}
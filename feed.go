@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ленты результатов тиражей в Atom и RSS 2.0 для порталов, которые забирают
+// контент только лентами. Последний проведённый тираж берётся из списка игр,
+// предыдущие — из архива по номерам; всё идёт через кеш, проведённые тиражи
+// в нём не устаревают.
+
+const (
+	// defaultFeedEntries и maxFeedEntries — размер ленты
+	defaultFeedEntries = 20
+	maxFeedEntries     = 50
+	// feedPrefetch — на сколько тиражей каждой игры запросы опережают ленту
+	feedPrefetch = 2
+	// feedTTL — подсказка агрегаторам, как часто перечитывать ленту
+	feedTTL = 15 * time.Minute
+)
+
+// FeedAtom godoc
+// @Summary Лента результатов тиражей (Atom)
+// @Description Последние проведённые тиражи с выигрышной комбинацией, суперпризом и победителями. Без name — по всем играм.
+// @Tags feed
+// @Produce application/atom+xml
+// @Security ApiKeyAuth
+// @Param name query []string false "Игры (можно несколько); без параметра — все" collectionFormat(multi)
+// @Param limit query int false "Сколько тиражей в ленте (до 50)" default(20)
+// @Success 200 {string} string "Atom feed"
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 404 {object} ErrorResponse "Игра не найдена"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/feed.atom [get]
+func handleFeedAtom(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, "application/atom+xml; charset=utf-8", buildAtom)
+}
+
+// FeedRSS godoc
+// @Summary Лента результатов тиражей (RSS)
+// @Description То же, что /api/feed.atom, в формате RSS 2.0.
+// @Tags feed
+// @Produce application/rss+xml
+// @Security ApiKeyAuth
+// @Param name query []string false "Игры (можно несколько); без параметра — все" collectionFormat(multi)
+// @Param limit query int false "Сколько тиражей в ленте (до 50)" default(20)
+// @Success 200 {string} string "RSS feed"
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 404 {object} ErrorResponse "Игра не найдена"
+// @Failure 502 {object} ErrorResponse "Ошибка апстрима"
+// @Router /api/feed.rss [get]
+func handleFeedRSS(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, "application/rss+xml; charset=utf-8", buildRSS)
+}
+
+// feed — общее содержимое лент до сериализации
+type feed struct {
+	id      string
+	title   string
+	self    string
+	updated time.Time
+	entries []feedEntry
+}
+
+type feedEntry struct {
+	game  upstreamGame
+	draw  DrawV1
+	title string
+	html  string
+}
+
+func serveFeed(w http.ResponseWriter, r *http.Request, contentType string, build func(*feed) any) {
+	limit := defaultFeedEntries
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxFeedEntries {
+			sendError(w, fmt.Sprintf("limit must be between 1 and %d", maxFeedEntries), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	games, header, err := fetchGames(r.Context())
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+	selected, err := selectGames(games, r.URL.Query()["name"])
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+
+	f, err := collectFeed(r.Context(), selected, limit)
+	if err != nil {
+		sendAPIError(w, err)
+		return
+	}
+	f.self = publicBaseURL() + r.URL.RequestURI()
+	f.title = "Результаты тиражей Столото"
+	f.id = "tag:stoloto-proxy,2024:feed/all"
+	if len(selected) == 1 {
+		f.title = "Результаты тиражей: " + selected[0].Title
+	}
+	if len(r.URL.Query()["name"]) > 0 {
+		names := make([]string, len(selected))
+		for i, g := range selected {
+			names[i] = g.Name
+		}
+		f.id = "tag:stoloto-proxy,2024:feed/" + strings.Join(names, ",")
+	}
+
+	body, err := xml.MarshalIndent(build(f), "", "  ")
+	if err != nil {
+		sendError(w, "Failed to encode feed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	serveBody(w, r, append([]byte(xml.Header), body...), gamesHints(games, time.Now()), header)
+}
+
+// collectFeed собирает limit самых свежих проведённых тиражей выбранных игр.
+// Тиражи каждой игры идут от последнего к первым, и в ленту каждый раз
+// попадает самый свежий из очередных тиражей всех игр. Более старые тиражи
+// игры запрашиваются, только пока её тиражи попадают в ленту.
+func collectFeed(ctx context.Context, games []upstreamGame, limit int) (*feed, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	type stream struct {
+		game    upstreamGame
+		results <-chan chan drawResult
+		head    *upstreamDraw
+	}
+	var streams []*stream
+	for _, g := range games {
+		if g.CompletedDraw == nil || g.CompletedDraw.Number <= 0 {
+			continue
+		}
+		last := g.CompletedDraw.Number
+		streams = append(streams, &stream{game: g, results: prefetchDraws(ctx, g.Name, last, max(1, last-limit+1), feedPrefetch)})
+	}
+	defer func() {
+		results := make([]<-chan chan drawResult, 0, len(streams))
+		for _, s := range streams {
+			results = append(results, s.results)
+		}
+		stopPrefetch(cancel, results...)
+	}()
+
+	// Первые тиражи всех игр запрашиваются одновременно
+	for _, s := range streams {
+		var err error
+		if s.head, err = nextDraw(s.results); err != nil {
+			return nil, err
+		}
+	}
+
+	f := &feed{}
+	for len(f.entries) < limit {
+		var newest *stream
+		for _, s := range streams {
+			if s.head != nil && (newest == nil || s.head.Date.After(newest.head.Date.Time)) {
+				newest = s
+			}
+		}
+		if newest == nil {
+			break
+		}
+
+		f.entries = append(f.entries, newFeedEntry(newest.game, toDrawV1(newest.game.Name, newest.head)))
+		var err error
+		if newest.head, err = nextDraw(newest.results); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(f.entries) > 0 {
+		f.updated = f.entries[0].draw.Date
+	}
+	return f, nil
+}
+
+func newFeedEntry(g upstreamGame, d DrawV1) feedEntry {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<p>Выигрышная комбинация: <b>%s</b></p>", html.EscapeString(formatCombination(g.Name, d.Numbers)))
+	fmt.Fprintf(&b, "<p>Суперприз: %s</p>", formatRubles(d.SuperPrize))
+	fmt.Fprintf(&b, "<p>Победителей: %d, выплачено: %s</p>", d.TotalWinners, formatRubles(d.TotalPrize))
+	if len(d.Prizes) > 0 {
+		b.WriteString("<table><tr><th>Угадано</th><th>Победителей</th><th>Выигрыш</th></tr>")
+		for _, p := range d.Prizes {
+			fmt.Fprintf(&b, "<tr><td>%s</td><td>%d</td><td>%s</td></tr>", html.EscapeString(p.Matched), p.Winners, formatRubles(p.Amount))
+		}
+		b.WriteString("</table>")
+	}
+
+	return feedEntry{
+		game:  g,
+		draw:  d,
+		title: fmt.Sprintf("%s: тираж №%d", g.Title, d.Number),
+		html:  b.String(),
+	}
+}
+
+// formatCombination разбивает шары по полям билета, если правила игры известны:
+// "3 7 12 25 31 | 2"
+func formatCombination(game string, numbers []int) string {
+	if len(numbers) == 0 {
+		return "—"
+	}
+	fields := gameRules[game]
+	total := 0
+	for _, f := range fields {
+		total += f.Pick
+	}
+	if total != len(numbers) {
+		fields = []ruleField{{Pick: len(numbers)}}
+	}
+
+	parts := make([]string, 0, len(fields))
+	rest := numbers
+	for _, f := range fields {
+		nums := make([]string, f.Pick)
+		for i, n := range rest[:f.Pick] {
+			nums[i] = strconv.Itoa(n)
+		}
+		parts = append(parts, strings.Join(nums, " "))
+		rest = rest[f.Pick:]
+	}
+	return strings.Join(parts, " | ")
+}
+
+// feedEntryID — постоянный идентификатор записи (tag URI, RFC 4151)
+func feedEntryID(game string, number int) string {
+	return fmt.Sprintf("tag:stoloto-proxy,2024:draw/%s/%d", game, number)
+}
+
+// Atom (RFC 4287)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string       `xml:"id"`
+	Title     string       `xml:"title"`
+	Updated   string       `xml:"updated"`
+	Published string       `xml:"published"`
+	Link      atomLink     `xml:"link"`
+	Category  atomCategory `xml:"category"`
+	Content   atomContent  `xml:"content"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func buildAtom(f *feed) any {
+	updated := f.updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	out := atomFeed{
+		ID:      f.id,
+		Title:   f.title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  atomPerson{Name: "Столото"},
+		Links:   []atomLink{{Rel: "self", Href: f.self}},
+	}
+	for _, e := range f.entries {
+		date := e.draw.Date.UTC().Format(time.RFC3339)
+		out.Entries = append(out.Entries, atomEntry{
+			ID:        feedEntryID(e.game.Name, e.draw.Number),
+			Title:     e.title,
+			Updated:   date,
+			Published: date,
+			Link:      atomLink{Rel: "alternate", Href: drawURL(e.game.Name, e.draw.Number)},
+			Category:  atomCategory{Term: e.game.Name, Label: e.game.Title},
+			Content:   atomContent{Type: "html", Body: e.html},
+		})
+	}
+	return out
+}
+
+// RSS 2.0
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	TTL           int       `xml:"ttl"`
+	Self          rssSelf   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssSelf — atom:link rel="self", который рекомендуют валидаторы RSS
+type rssSelf struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Category    string  `xml:"category"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func buildRSS(f *feed) any {
+	out := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.title,
+			Link:        publicBaseURL() + "/api/games",
+			Description: "Выигрышные комбинации, суперпризы и победители последних тиражей",
+			Language:    "ru",
+			TTL:         int(feedTTL.Minutes()),
+			Self:        rssSelf{Rel: "self", Type: "application/rss+xml", Href: f.self},
+		},
+	}
+	if !f.updated.IsZero() {
+		out.Channel.LastBuildDate = f.updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, e := range f.entries {
+		out.Channel.Items = append(out.Channel.Items, rssItem{
+			Title:       e.title,
+			Link:        drawURL(e.game.Name, e.draw.Number),
+			GUID:        rssGUID{Value: feedEntryID(e.game.Name, e.draw.Number)},
+			PubDate:     e.draw.Date.UTC().Format(time.RFC1123Z),
+			Category:    e.game.Title,
+			Description: e.html,
+		})
+	}
+	return out
+}
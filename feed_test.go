@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFeedStopsFetchingOlderDraws(t *testing.T) {
+	fake, proxy := startProxy(t, func(c *Config) {
+		c.UpstreamBudget = UpstreamBudgetConfig{}
+	})
+
+	resp, err := http.Get(proxy.URL + "/api/feed.atom?limit=50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	var feed atomFeed
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Entries) != 50 {
+		t.Fatalf("entries = %d, want 50", len(feed.Entries))
+	}
+
+	dates := make([]time.Time, len(feed.Entries))
+	for i, e := range feed.Entries {
+		if dates[i], err = time.Parse(time.RFC3339, e.Updated); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.IsSortedFunc(dates, func(a, b time.Time) int { return b.Compare(a) }) {
+		t.Fatalf("entries are not ordered newest first: %v", dates)
+	}
+
+	// Шесть тиражных игр по 50 тиражей — 300 запросов без остановки. Нужно
+	// 50 тиражей ленты и по нескольку запрошенных впрок у каждой игры.
+	if n, want := countRequests(fake, "/service/draws/"), 50+6*(feedPrefetch+2); n > want {
+		t.Fatalf("upstream draw requests = %d, want at most %d", n, want)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Фильтрация, сортировка и постраничная выдача списка лотерей в API v1.
+// Список небольшой и приходит от Stoloto целиком, поэтому всё делается
+// в памяти над типизированной моделью.
+
+const maxGamesLimit = 100
+
+var errInvalidGamesQuery = errors.New("invalid query")
+
+// gamesQuery — разобранные параметры запроса списка лотерей
+type gamesQuery struct {
+	types     []string
+	active    *bool
+	salesOpen *bool
+	// pattern — шаблон path.Match в нижнем регистре
+	pattern string
+	sortBy  string
+	desc    bool
+	limit   int
+	cursor  *gamesCursor
+}
+
+// gamesCursor — содержимое курсора: сортировка и ключ последней лотереи
+// страницы. Клиенту он отдаётся непрозрачной строкой (base64 от JSON).
+type gamesCursor struct {
+	Sort string `json:"s,omitempty"`
+	Desc bool   `json:"d,omitempty"`
+	Name string `json:"n"`
+	// Current — поля текущего тиража, по которым сортируется список
+	Current *cursorDraw `json:"c,omitempty"`
+	// Next — сколько лотерей выдано; нужно только без сортировки
+	Next int `json:"i,omitempty"`
+}
+
+type cursorDraw struct {
+	SuperPrize int64     `json:"p"`
+	Date       time.Time `json:"t"`
+}
+
+func newGamesCursor(gq gamesQuery, last GameV1, next int) string {
+	c := gamesCursor{Sort: gq.sortBy, Desc: gq.desc, Name: last.Name}
+	if gq.sortBy == "" {
+		c.Next = next
+	} else if last.CurrentDraw != nil {
+		c.Current = &cursorDraw{SuperPrize: last.CurrentDraw.SuperPrize, Date: last.CurrentDraw.Date}
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func parseGamesCursor(v string) (*gamesCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(v)
+	var c gamesCursor
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.Name == "" {
+		return nil, fmt.Errorf("%w: bad cursor", errInvalidGamesQuery)
+	}
+	return &c, nil
+}
+
+// game восстанавливает из курсора лотерею, достаточную для сравнения
+func (c *gamesCursor) game() GameV1 {
+	g := GameV1{Name: c.Name}
+	if c.Current != nil {
+		g.CurrentDraw = &DrawV1{SuperPrize: c.Current.SuperPrize, Date: c.Current.Date}
+	}
+	return g
+}
+
+// gameSort — поле сортировки. Лотереи без значения (has == false) идут
+// в конце при любом направлении, между собой в исходном порядке.
+type gameSort struct {
+	has     func(g GameV1) bool
+	compare func(a, b GameV1) int
+}
+
+var gamesSorts = map[string]gameSort{
+	"name": {
+		has:     func(GameV1) bool { return true },
+		compare: func(a, b GameV1) int { return strings.Compare(a.Name, b.Name) },
+	},
+	"superPrize": {
+		has:     func(g GameV1) bool { return g.CurrentDraw != nil },
+		compare: func(a, b GameV1) int { return cmp.Compare(a.CurrentDraw.SuperPrize, b.CurrentDraw.SuperPrize) },
+	},
+	"nextDraw": {
+		has:     func(g GameV1) bool { return g.CurrentDraw != nil && !g.CurrentDraw.Date.IsZero() },
+		compare: func(a, b GameV1) int { return a.CurrentDraw.Date.Compare(b.CurrentDraw.Date) },
+	},
+}
+
+func parseGamesQuery(q url.Values) (gamesQuery, error) {
+	var gq gamesQuery
+	for _, v := range q["type"] {
+		for _, t := range strings.Split(v, ",") {
+			switch t = strings.TrimSpace(t); t {
+			case "":
+			case "draw", "bingo", "instant":
+				gq.types = append(gq.types, t)
+			default:
+				return gq, fmt.Errorf("%w: unknown type %q, expected draw, bingo or instant", errInvalidGamesQuery, t)
+			}
+		}
+	}
+
+	for name, dst := range map[string]**bool{"active": &gq.active, "salesOpen": &gq.salesOpen} {
+		if v := q.Get(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return gq, fmt.Errorf("%w: %s must be true or false", errInvalidGamesQuery, name)
+			}
+			*dst = &b
+		}
+	}
+
+	if v := q.Get("name"); v != "" {
+		gq.pattern = strings.ToLower(v)
+		// Без метасимволов шаблон — подстрока
+		if !strings.ContainsAny(gq.pattern, `*?[\`) {
+			gq.pattern = "*" + gq.pattern + "*"
+		}
+		if _, err := path.Match(gq.pattern, ""); err != nil {
+			return gq, fmt.Errorf("%w: bad name pattern", errInvalidGamesQuery)
+		}
+	}
+
+	if v := q.Get("sort"); v != "" {
+		gq.sortBy, gq.desc = strings.CutPrefix(v, "-")
+		if _, ok := gamesSorts[gq.sortBy]; !ok {
+			return gq, fmt.Errorf("%w: unknown sort %q, expected name, superPrize or nextDraw", errInvalidGamesQuery, v)
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxGamesLimit {
+			return gq, fmt.Errorf("%w: limit must be between 1 and %d", errInvalidGamesQuery, maxGamesLimit)
+		}
+		gq.limit = n
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		c, err := parseGamesCursor(v)
+		if err != nil {
+			return gq, err
+		}
+		if c.Sort != gq.sortBy || c.Desc != gq.desc {
+			return gq, fmt.Errorf("%w: cursor belongs to a listing with another sort", errInvalidGamesQuery)
+		}
+		gq.cursor = c
+	}
+	return gq, nil
+}
+
+func (gq gamesQuery) match(g GameV1) bool {
+	if len(gq.types) > 0 && !slices.Contains(gq.types, g.Type) {
+		return false
+	}
+	if gq.active != nil && (g.CurrentDraw != nil) != *gq.active {
+		return false
+	}
+	if gq.salesOpen != nil && g.SalesOpen != *gq.salesOpen {
+		return false
+	}
+	if gq.pattern != "" {
+		byName, _ := path.Match(gq.pattern, strings.ToLower(g.Name))
+		byTitle, _ := path.Match(gq.pattern, strings.ToLower(g.Title))
+		if !byName && !byTitle {
+			return false
+		}
+	}
+	return true
+}
+
+// compare — порядок сортировки: лотереи без значения в конце, равные по
+// значению — по имени, так что у каждой лотереи своё место в списке
+func (gq gamesQuery) compare(sort gameSort, a, b GameV1) int {
+	aHas, bHas := sort.has(a), sort.has(b)
+	switch {
+	case aHas && !bHas:
+		return -1
+	case !aHas && bHas:
+		return 1
+	}
+	if aHas {
+		c := sort.compare(a, b)
+		if gq.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a.Name, b.Name)
+}
+
+// apply возвращает страницу списка, число подходящих лотерей и курсор
+// следующей страницы. При сортировке курсор хранит ключ последней лотереи
+// страницы, и выдача продолжается со следующей за ним позиции — даже если
+// сама лотерея из списка пропала. Без сортировки порядок задаёт Stoloto:
+// выдача продолжается после лотереи из курсора, а если её уже нет — с того
+// же номера по счёту.
+func (gq gamesQuery) apply(games []GameV1) ([]GameV1, int, string) {
+	var out []GameV1
+	for _, g := range games {
+		if gq.match(g) {
+			out = append(out, g)
+		}
+	}
+
+	sort, sorted := gamesSorts[gq.sortBy]
+	if sorted {
+		slices.SortFunc(out, func(a, b GameV1) int { return gq.compare(sort, a, b) })
+	}
+	total := len(out)
+
+	start := 0
+	if c := gq.cursor; c != nil {
+		switch i := slices.IndexFunc(out, func(g GameV1) bool { return g.Name == c.Name }); {
+		case sorted:
+			after := c.game()
+			start, _ = slices.BinarySearchFunc(out, after, func(g, after GameV1) int {
+				if gq.compare(sort, g, after) <= 0 {
+					return -1
+				}
+				return 1
+			})
+		case i >= 0:
+			start = i + 1
+		default:
+			start = min(c.Next, len(out))
+		}
+	}
+	out = out[start:]
+
+	var next string
+	if gq.limit > 0 && len(out) > gq.limit {
+		out = out[:gq.limit]
+		next = newGamesCursor(gq, out[len(out)-1], start+len(out))
+	}
+	return out, total, next
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+// gamesPage запрашивает страницу списка игр и проверяет, что ответ успешный
+func gamesPage(t *testing.T, proxy string, query url.Values) GamesV1 {
+	t.Helper()
+
+	var page GamesV1
+	if resp := getJSON(t, proxy+"/api/v1/games?"+query.Encode(), &page); resp.StatusCode != http.StatusOK {
+		t.Fatalf("games %s: status %d", query.Encode(), resp.StatusCode)
+	}
+	return page
+}
+
+func gameNames(games []GameV1) []string {
+	names := make([]string, 0, len(games))
+	for _, g := range games {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+func TestGamesPagination(t *testing.T) {
+	_, proxy := startProxy(t, nil)
+
+	want := []string{"ruslotto", "4x20", "housing", "6x45", "7x49", "5x36plus", "momental"}
+	for _, sort := range []string{"", "name", "-superPrize"} {
+		query := url.Values{"limit": {"2"}}
+		if sort != "" {
+			query.Set("sort", sort)
+		}
+		full := gamesPage(t, proxy.URL, url.Values{"sort": query["sort"]})
+
+		var got []string
+		for pages := 0; ; pages++ {
+			if pages > len(full.Games) {
+				t.Fatalf("sort=%q: pagination does not end", sort)
+			}
+			page := gamesPage(t, proxy.URL, query)
+			if page.Total != len(full.Games) {
+				t.Fatalf("sort=%q: total = %d, want %d", sort, page.Total, len(full.Games))
+			}
+			got = append(got, gameNames(page.Games)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Set("cursor", page.NextCursor)
+		}
+		if !slices.Equal(got, gameNames(full.Games)) {
+			t.Fatalf("sort=%q: pages = %v, want %v", sort, got, gameNames(full.Games))
+		}
+		if sort == "-superPrize" && !slices.Equal(got, want) {
+			t.Fatalf("sort=-superPrize: order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestGamesCursorSurvivesListChanges(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+
+	query := url.Values{"sort": {"-superPrize"}, "limit": {"2"}}
+	first := gamesPage(t, proxy.URL, query)
+	if names := gameNames(first.Games); !slices.Equal(names, []string{"ruslotto", "4x20"}) {
+		t.Fatalf("first page = %v", names)
+	}
+
+	// последняя игра страницы уходит в конец списка: продолжаем после её
+	// прежнего места, а не после нового
+	if err := fake.SetSuperPrize("4x20", 1); err != nil {
+		t.Fatal(err)
+	}
+	query.Set("cursor", first.NextCursor)
+	if names := gameNames(gamesPage(t, proxy.URL, query).Games); !slices.Equal(names, []string{"housing", "6x45"}) {
+		t.Fatalf("page after moved game = %v, want [housing 6x45]", names)
+	}
+
+	// игры из курсора нет под фильтром — страница всё равно продолжается
+	query.Set("type", "bingo")
+	if names := gameNames(gamesPage(t, proxy.URL, query).Games); !slices.Equal(names, []string{"housing"}) {
+		t.Fatalf("page after missing game = %v, want [housing]", names)
+	}
+}
+
+func TestGamesCursorErrors(t *testing.T) {
+	_, proxy := startProxy(t, nil)
+
+	page := gamesPage(t, proxy.URL, url.Values{"sort": {"name"}, "limit": {"2"}})
+	for _, query := range []url.Values{
+		{"cursor": {"7x49"}},
+		{"cursor": {page.NextCursor}, "sort": {"-name"}},
+		{"cursor": {page.NextCursor}},
+	} {
+		if resp := getJSON(t, proxy.URL+"/api/v1/games?"+query.Encode(), nil); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("games %s: status %d, want 400", query.Encode(), resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,590 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/dataloader"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// GraphQL поверх тех же запросов к Stoloto, что и REST API. В пределах одного
+// запроса список игр, каждый тираж и карточки моментальных лотерей
+// загружаются не больше одного раза, а тиражи истории — параллельно.
+
+const graphqlSchemaSDL = `
+schema {
+	query: Query
+}
+
+type Query {
+	games: [Game!]!
+	game(name: String!): Game
+	draw(game: String!, number: Int!): Draw
+	momentCards: [MomentCard!]!
+}
+
+type Game {
+	name: String!
+	title: String!
+	type: String!
+	salesOpen: Boolean!
+	# Правила числовой лотереи; у бинго-лотерей их нет
+	rules: [RuleField!]
+	# Суперприз тиража, на который идут продажи
+	superPrize: Float
+	currentDraw: Draw
+	completedDraw: Draw
+	# Проведённые тиражи от последнего к первым
+	history(first: Int = 10, after: String): DrawConnection!
+}
+
+type RuleField {
+	pick: Int!
+	from: Int!
+}
+
+enum DrawStatus {
+	OPEN
+	COMPLETED
+}
+
+type Draw {
+	game: String!
+	number: Int!
+	# RFC 3339
+	date: String!
+	status: DrawStatus!
+	superPrize: Float!
+	combination: [Int!]!
+	prizeCategories: [PrizeCategory!]!
+	totalWinners: Int!
+	totalPrize: Float!
+}
+
+type PrizeCategory {
+	category: Int!
+	matched: String!
+	winners: Int!
+	amount: Float!
+}
+
+type DrawConnection {
+	edges: [DrawEdge!]!
+	pageInfo: PageInfo!
+}
+
+type DrawEdge {
+	cursor: String!
+	node: Draw!
+}
+
+type PageInfo {
+	hasNextPage: Boolean!
+	endCursor: String
+}
+
+type MomentCard {
+	id: ID!
+	name: String!
+	price: Float!
+	maxPrize: Float!
+	available: Boolean!
+	badge: String
+	imageSmall: String
+	imageLarge: String
+}
+`
+
+// maxHistoryPage ограничивает число тиражей, которые один запрос истории
+// может загрузить из апстрима
+const maxHistoryPage = 50
+
+// maxGraphQLDraws ограничивает число разных тиражей на весь запрос: иначе
+// history по всем играм и через псевдонимы стоит сотен запросов к апстриму
+const maxGraphQLDraws = 200
+
+// maxGraphQLBodyBytes — предельный размер тела POST-запроса
+const maxGraphQLBodyBytes = 64 << 10
+
+var graphqlSchema = graphql.MustParseSchema(graphqlSchemaSDL, &graphqlResolver{},
+	graphql.MaxDepth(8),
+	graphql.MaxParallelism(10),
+)
+
+// GraphQL godoc
+// @Summary GraphQL
+// @Description Игры, тиражи с историей и моментальные лотереи одним запросом. Схема доступна через интроспекцию.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param query query string false "Запрос (для GET)"
+// @Param request body graphqlRequest false "Запрос (для POST)"
+// @Success 200 {object} map[string]interface{} "data и errors"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Router /graphql [get]
+// @Router /graphql [post]
+func handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	if r.Method == http.MethodPost {
+		r.Body = http.MaxBytesReader(w, r.Body, maxGraphQLBodyBytes)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				sendError(w, fmt.Sprintf("Request body exceeds %d bytes", maxGraphQLBodyBytes), http.StatusRequestEntityTooLarge)
+				return
+			}
+			sendError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				sendError(w, "Invalid variables: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if req.Query == "" {
+		sendError(w, `Missing required parameter: "query"`, http.StatusBadRequest)
+		return
+	}
+
+	loaders := newGraphQLLoaders()
+	ctx := context.WithValue(r.Context(), graphqlLoadersKey{}, loaders)
+	resp := graphqlSchema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	// Ответ собран из данных с разным сроком жизни, кешировать его целиком нельзя
+	w.Header().Set("Cache-Control", "no-store")
+	if stale := loaders.staleHeader(); stale != nil {
+		for _, name := range proxyMarkers {
+			if v := stale.Get(name); v != "" {
+				w.Header().Set(name, v)
+			}
+		}
+	}
+	sendJSON(w, resp, http.StatusOK)
+}
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Загрузчики на время одного запроса
+
+type graphqlLoadersKey struct{}
+
+type graphqlLoaders struct {
+	games    *dataloader.Loader
+	draws    *dataloader.Loader
+	momental *dataloader.Loader
+
+	mu    sync.Mutex
+	stale http.Header
+	// drawKeys — тиражи, уже запрошенные в этом запросе
+	drawKeys map[drawKey]struct{}
+}
+
+// drawKey — ключ тиража для загрузчика
+type drawKey struct {
+	game   string
+	number int
+}
+
+func (k drawKey) String() string   { return k.game + "/" + strconv.Itoa(k.number) }
+func (k drawKey) Raw() interface{} { return k }
+
+func newGraphQLLoaders() *graphqlLoaders {
+	l := &graphqlLoaders{}
+	l.games = dataloader.NewBatchedLoader(l.batchGames)
+	l.draws = dataloader.NewBatchedLoader(l.batchDraws, dataloader.WithWait(time.Millisecond))
+	l.momental = dataloader.NewBatchedLoader(l.batchMomental)
+	return l
+}
+
+func loadersFrom(ctx context.Context) *graphqlLoaders {
+	return ctx.Value(graphqlLoadersKey{}).(*graphqlLoaders)
+}
+
+// note запоминает заголовки ответа апстрима, если данные в нём устарели
+func (l *graphqlLoaders) note(header http.Header) {
+	if header.Get("X-Data-Stale") == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stale == nil || ageSeconds(header) > ageSeconds(l.stale) {
+		l.stale = header
+	}
+}
+
+// reserveDraws учитывает тиражи, которые запрос собирается загрузить, и
+// отказывает, если вместе с уже загруженными их больше maxGraphQLDraws
+func (l *graphqlLoaders) reserveDraws(keys ...drawKey) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.drawKeys == nil {
+		l.drawKeys = make(map[drawKey]struct{})
+	}
+	added := 0
+	for _, k := range keys {
+		if _, ok := l.drawKeys[k]; !ok {
+			added++
+		}
+	}
+	if len(l.drawKeys)+added > maxGraphQLDraws {
+		return fmt.Errorf("query loads more than %d draws", maxGraphQLDraws)
+	}
+	for _, k := range keys {
+		l.drawKeys[k] = struct{}{}
+	}
+	return nil
+}
+
+func (l *graphqlLoaders) staleHeader() http.Header {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stale
+}
+
+// batchGames и batchMomental грузят единственный ресурс на любой набор ключей
+func (l *graphqlLoaders) batchGames(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	games, header, err := fetchGames(ctx)
+	l.note(header)
+	return sameResult(len(keys), games, err)
+}
+
+func (l *graphqlLoaders) batchMomental(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	cards, header, err := fetchMomentalCards(ctx, momentalOptions{})
+	l.note(header)
+	return sameResult(len(keys), cards, err)
+}
+
+func sameResult(n int, data any, err error) []*dataloader.Result {
+	results := make([]*dataloader.Result, n)
+	for i := range results {
+		results[i] = &dataloader.Result{Data: data, Error: err}
+	}
+	return results
+}
+
+// batchDraws: у Stoloto нет пакетного запроса тиражей, поэтому тиражи пачки
+// запрашиваются параллельно; очередность и темп задаёт бюджет апстрима
+func (l *graphqlLoaders) batchDraws(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	results := make([]*dataloader.Result, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k := key.Raw().(drawKey)
+			draw, header, err := fetchDraw(ctx, k.game, k.number)
+			l.note(header)
+			results[i] = &dataloader.Result{Data: draw, Error: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func loadGames(ctx context.Context) (*upstreamGamesResponse, error) {
+	v, err := loadersFrom(ctx).games.Load(ctx, dataloader.StringKey("games"))()
+	if err != nil {
+		return nil, err
+	}
+	return v.(*upstreamGamesResponse), nil
+}
+
+func loadDraw(ctx context.Context, game string, number int) (*upstreamDraw, error) {
+	loaders := loadersFrom(ctx)
+	if err := loaders.reserveDraws(drawKey{game, number}); err != nil {
+		return nil, err
+	}
+	v, err := loaders.draws.Load(ctx, drawKey{game, number})()
+	if err != nil {
+		return nil, err
+	}
+	return v.(*upstreamDraw), nil
+}
+
+// Резолверы
+
+type graphqlResolver struct{}
+
+func (*graphqlResolver) Games(ctx context.Context) ([]*gameResolver, error) {
+	games, err := loadGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*gameResolver, len(games.Games))
+	for i := range games.Games {
+		out[i] = &gameResolver{&games.Games[i]}
+	}
+	return out, nil
+}
+
+func (*graphqlResolver) Game(ctx context.Context, args struct{ Name string }) (*gameResolver, error) {
+	games, err := loadGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if g := games.find(args.Name); g != nil {
+		return &gameResolver{g}, nil
+	}
+	return nil, nil
+}
+
+func (*graphqlResolver) Draw(ctx context.Context, args struct {
+	Game   string
+	Number int32
+}) (*drawResolver, error) {
+	draw, err := loadDraw(ctx, args.Game, int(args.Number))
+	if errors.Is(err, errDrawNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &drawResolver{args.Game, draw}, nil
+}
+
+func (*graphqlResolver) MomentCards(ctx context.Context) ([]*momentCardResolver, error) {
+	v, err := loadersFrom(ctx).momental.Load(ctx, dataloader.StringKey("momental"))()
+	if err != nil {
+		return nil, err
+	}
+
+	cards := v.([]upstreamCard)
+	out := make([]*momentCardResolver, len(cards))
+	for i := range cards {
+		out[i] = &momentCardResolver{&cards[i]}
+	}
+	return out, nil
+}
+
+type gameResolver struct {
+	g *upstreamGame
+}
+
+func (r *gameResolver) Name() string    { return r.g.Name }
+func (r *gameResolver) Title() string   { return r.g.Title }
+func (r *gameResolver) Type() string    { return r.g.Type }
+func (r *gameResolver) SalesOpen() bool { return r.g.SalesOpen }
+func (r *gameResolver) Rules() *[]*ruleFieldResolver {
+	fields, ok := gameRules[r.g.Name]
+	if !ok {
+		return nil
+	}
+	out := make([]*ruleFieldResolver, len(fields))
+	for i := range fields {
+		out[i] = &ruleFieldResolver{fields[i]}
+	}
+	return &out
+}
+
+func (r *gameResolver) SuperPrize() *float64 {
+	if r.g.Draw == nil {
+		return nil
+	}
+	return &r.g.Draw.SuperPrize
+}
+
+func (r *gameResolver) CurrentDraw() *drawResolver {
+	if r.g.Draw == nil {
+		return nil
+	}
+	return &drawResolver{r.g.Name, r.g.Draw}
+}
+
+// CompletedDraw загружается отдельно: в списке игр у тиража нет выигрышных категорий
+func (r *gameResolver) CompletedDraw(ctx context.Context) (*drawResolver, error) {
+	if r.g.CompletedDraw == nil {
+		return nil, nil
+	}
+	draw, err := loadDraw(ctx, r.g.Name, r.g.CompletedDraw.Number)
+	if err != nil {
+		return nil, err
+	}
+	return &drawResolver{r.g.Name, draw}, nil
+}
+
+func (r *gameResolver) History(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (*drawConnectionResolver, error) {
+	if args.First < 0 || args.First > maxHistoryPage {
+		return nil, fmt.Errorf("first must be between 0 and %d", maxHistoryPage)
+	}
+
+	conn := &drawConnectionResolver{}
+	if r.g.CompletedDraw == nil {
+		return conn, nil
+	}
+
+	// Курсор — номер тиража; страница начинается с предыдущего
+	from := r.g.CompletedDraw.Number
+	if args.After != nil {
+		n, err := strconv.Atoi(*args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %q", *args.After)
+		}
+		from = n - 1
+	}
+
+	var keys dataloader.Keys
+	var reserve []drawKey
+	for n := from; n > 0 && len(keys) < int(args.First); n-- {
+		keys = append(keys, drawKey{r.g.Name, n})
+		reserve = append(reserve, drawKey{r.g.Name, n})
+	}
+
+	loaders := loadersFrom(ctx)
+	if err := loaders.reserveDraws(reserve...); err != nil {
+		return nil, err
+	}
+	draws, errs := loaders.draws.LoadMany(ctx, keys)()
+	for i, key := range keys {
+		if errs != nil && errs[i] != nil {
+			if errors.Is(errs[i], errDrawNotFound) {
+				continue
+			}
+			return nil, errs[i]
+		}
+		conn.edges = append(conn.edges, &drawEdgeResolver{
+			cursor: strconv.Itoa(key.Raw().(drawKey).number),
+			node:   &drawResolver{r.g.Name, draws[i].(*upstreamDraw)},
+		})
+	}
+	if len(keys) > 0 {
+		last := keys[len(keys)-1].Raw().(drawKey).number
+		conn.hasNext = last > 1
+		conn.endCursor = strconv.Itoa(last)
+	}
+	return conn, nil
+}
+
+type ruleFieldResolver struct {
+	f ruleField
+}
+
+func (r *ruleFieldResolver) Pick() int32 { return int32(r.f.Pick) }
+func (r *ruleFieldResolver) From() int32 { return int32(r.f.From) }
+
+type drawResolver struct {
+	game string
+	d    *upstreamDraw
+}
+
+func (r *drawResolver) Game() string        { return r.game }
+func (r *drawResolver) Number() int32       { return int32(r.d.Number) }
+func (r *drawResolver) Date() string        { return r.d.Date.Format(time.RFC3339) }
+func (r *drawResolver) SuperPrize() float64 { return r.d.SuperPrize }
+func (r *drawResolver) TotalWinners() int32 { return int32(r.d.TotalWinners) }
+func (r *drawResolver) TotalPrize() float64 { return r.d.TotalPrize }
+
+func (r *drawResolver) Status() string {
+	if r.d.completed() {
+		return "COMPLETED"
+	}
+	return "OPEN"
+}
+
+func (r *drawResolver) Combination() []int32 {
+	out := []int32{}
+	for _, n := range toDrawV1(r.game, r.d).Numbers {
+		out = append(out, int32(n))
+	}
+	return out
+}
+
+func (r *drawResolver) PrizeCategories() []*prizeCategoryResolver {
+	out := make([]*prizeCategoryResolver, len(r.d.WinningCategories))
+	for i := range r.d.WinningCategories {
+		out[i] = &prizeCategoryResolver{&r.d.WinningCategories[i]}
+	}
+	return out
+}
+
+type prizeCategoryResolver struct {
+	c *upstreamCategory
+}
+
+func (r *prizeCategoryResolver) Category() int32 { return int32(r.c.Number) }
+func (r *prizeCategoryResolver) Matched() string { return r.c.Combination }
+func (r *prizeCategoryResolver) Winners() int32  { return int32(r.c.Participants) }
+func (r *prizeCategoryResolver) Amount() float64 { return r.c.Amount }
+
+type drawConnectionResolver struct {
+	edges     []*drawEdgeResolver
+	hasNext   bool
+	endCursor string
+}
+
+func (r *drawConnectionResolver) Edges() []*drawEdgeResolver {
+	if r.edges == nil {
+		return []*drawEdgeResolver{}
+	}
+	return r.edges
+}
+
+func (r *drawConnectionResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{r}
+}
+
+type pageInfoResolver struct {
+	c *drawConnectionResolver
+}
+
+func (r *pageInfoResolver) HasNextPage() bool { return r.c.hasNext }
+func (r *pageInfoResolver) EndCursor() *string {
+	if r.c.endCursor == "" {
+		return nil
+	}
+	return &r.c.endCursor
+}
+
+type drawEdgeResolver struct {
+	cursor string
+	node   *drawResolver
+}
+
+func (r *drawEdgeResolver) Cursor() string      { return r.cursor }
+func (r *drawEdgeResolver) Node() *drawResolver { return r.node }
+
+type momentCardResolver struct {
+	c *upstreamCard
+}
+
+func (r *momentCardResolver) ID() graphql.ID    { return graphql.ID(r.c.ID) }
+func (r *momentCardResolver) Name() string      { return r.c.Name }
+func (r *momentCardResolver) Price() float64    { return r.c.Price }
+func (r *momentCardResolver) MaxPrize() float64 { return r.c.MaxPrize }
+func (r *momentCardResolver) Available() bool   { return r.c.Available }
+func (r *momentCardResolver) Badge() *string    { return optional(r.c.Badge) }
+func (r *momentCardResolver) ImageSmall() *string {
+	return optional(r.c.Images.Small)
+}
+func (r *momentCardResolver) ImageLarge() *string {
+	return optional(r.c.Images.Large)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// postGraphQL отправляет запрос и возвращает код ответа и тело
+func postGraphQL(t *testing.T, url string, body []byte) (int, map[string]any) {
+	t.Helper()
+
+	resp, err := http.Post(url+"/graphql", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	return resp.StatusCode, out
+}
+
+func TestGraphQLHistory(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+
+	query, _ := json.Marshal(graphqlRequest{Query: `{ game(name: "6x45") { history(first: 3) { edges { node { number } } } } }`})
+	status, out := postGraphQL(t, proxy.URL, query)
+	if status != http.StatusOK || out["errors"] != nil {
+		t.Fatalf("status %d, response %v", status, out)
+	}
+
+	edges := out["data"].(map[string]any)["game"].(map[string]any)["history"].(map[string]any)["edges"].([]any)
+	if len(edges) != 3 {
+		t.Fatalf("history edges = %d, want 3", len(edges))
+	}
+	first := edges[0].(map[string]any)["node"].(map[string]any)["number"].(float64)
+	if want := fake.CurrentDraw("6x45") - 1; int(first) != want {
+		t.Fatalf("first history draw = %v, want %d", first, want)
+	}
+}
+
+func TestGraphQLDrawLimit(t *testing.T) {
+	fake, proxy := startProxy(t, func(c *Config) {
+		c.UpstreamBudget = UpstreamBudgetConfig{}
+	})
+
+	// Пять псевдонимов по 50 тиражей — 250 разных тиражей
+	var fields []string
+	for i := range 5 {
+		fields = append(fields, fmt.Sprintf(`h%d: history(first: 50, after: "%d") { edges { cursor } }`, i, 1000-50*i))
+	}
+	query, _ := json.Marshal(graphqlRequest{Query: `{ game(name: "6x45") { ` + strings.Join(fields, " ") + ` } }`})
+
+	_, out := postGraphQL(t, proxy.URL, query)
+	if out["errors"] == nil || !strings.Contains(fmt.Sprint(out["errors"]), "more than 200 draws") {
+		t.Fatalf("errors = %v, want draw limit error", out["errors"])
+	}
+	if n := countRequests(fake, "/service/draws/"); n > maxGraphQLDraws {
+		t.Fatalf("upstream draw requests = %d, want at most %d", n, maxGraphQLDraws)
+	}
+}
+
+func TestGraphQLBodyLimit(t *testing.T) {
+	_, proxy := startProxy(t, nil)
+
+	body, _ := json.Marshal(graphqlRequest{Query: "{ games { name } }" + strings.Repeat(" ", maxGraphQLBodyBytes)})
+	status, _ := postGraphQL(t, proxy.URL, body)
+	if status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", status)
+	}
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	stolotov1 "DOUPIG/proto/stoloto/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCConfig — gRPC-сервер StolotoService (proto/stoloto/v1/stoloto.proto).
+// Без Addr не запускается. При включённом TLS использует те же сертификаты,
+// что и HTTP; API-ключ передаётся в метаданных x-api-key.
+type GRPCConfig struct {
+	Addr string `json:"addr"`
+	// Reflection — для grpcurl и подобных клиентов
+	Reflection bool `json:"reflection"`
+}
+
+// maxListDraws ограничивает диапазон одного вызова ListDraws
+const maxListDraws = 1000
+
+type grpcServer struct {
+	srv    *grpc.Server
+	health *health.Server
+	addr   string
+	// done закрывается при остановке, чтобы бесконечные потоки WatchDraws
+	// не держали GracefulStop
+	done chan struct{}
+}
+
+func newGRPCServer(tlsConfig *tls.Config) *grpcServer {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(grpcAuthUnary),
+		grpc.ChainStreamInterceptor(grpcAuthStream),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := &grpcServer{
+		srv:    grpc.NewServer(opts...),
+		health: health.NewServer(),
+		addr:   cfg.GRPC.Addr,
+		done:   make(chan struct{}),
+	}
+	stolotov1.RegisterStolotoServiceServer(s.srv, &stolotoService{done: s.done})
+	healthpb.RegisterHealthServer(s.srv, s.health)
+	s.health.SetServingStatus(stolotov1.StolotoService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	if cfg.GRPC.Reflection {
+		reflection.Register(s.srv)
+	}
+	return s
+}
+
+func (s *grpcServer) listenAndServe() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.srv.Serve(lis)
+}
+
+// shutdown переводит health в NOT_SERVING, завершает потоки WatchDraws и
+// ждёт остальные вызовы; по истечении ctx соединения закрываются
+func (s *grpcServer) shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+	close(s.done)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
+
+// Аутентификация. Health и reflection открыты: ими пользуются балансировщики
+// и отладочные клиенты.
+
+func grpcAuthUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := grpcAuthorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func grpcAuthStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := grpcAuthorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func grpcAuthorize(ctx context.Context, method string) error {
+	if !cfg.Auth.Enabled || !strings.HasPrefix(method, "/"+stolotov1.StolotoService_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+
+	var plain string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-api-key"); len(v) > 0 {
+			plain = v[0]
+		}
+	}
+
+	_, err := verifyAPIKey(plain, scopeDrawsRead)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errAPIKeyForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, errAPIKeyMissing), errors.Is(err, errAPIKeyInvalid), errors.Is(err, errAPIKeyExpired):
+		return status.Error(codes.Unauthenticated, err.Error())
+	default:
+		return status.Error(codes.Internal, "error checking API key")
+	}
+}
+
+// grpcError переводит ошибку получения данных в статус gRPC
+func grpcError(err error) error {
+	var statusErr *upstreamStatusError
+	switch {
+	case errors.Is(err, errDrawNotFound), errors.Is(err, errGameNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errUpstreamQueueFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.As(err, &statusErr):
+		return status.Error(codes.Unavailable, err.Error())
+	case upstreamErrorStatus(err) == http.StatusGatewayTimeout:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
+
+type stolotoService struct {
+	stolotov1.UnimplementedStolotoServiceServer
+	done <-chan struct{}
+}
+
+func (s *stolotoService) GetGames(ctx context.Context, _ *stolotov1.GetGamesRequest) (*stolotov1.GetGamesResponse, error) {
+	games, _, err := fetchGames(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &stolotov1.GetGamesResponse{}
+	for i := range games.Games {
+		resp.Games = append(resp.Games, toProtoGame(toGameV1(&games.Games[i])))
+	}
+	return resp, nil
+}
+
+func (s *stolotoService) GetDraw(ctx context.Context, req *stolotov1.GetDrawRequest) (*stolotov1.Draw, error) {
+	if req.Game == "" || req.Number <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "game and positive number are required")
+	}
+
+	draw, _, err := fetchDraw(ctx, req.Game, int(req.Number))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toProtoDraw(toDrawV1(req.Game, draw)), nil
+}
+
+func (s *stolotoService) GetLatestDraw(ctx context.Context, req *stolotov1.GetLatestDrawRequest) (*stolotov1.Draw, error) {
+	if req.Game == "" {
+		return nil, status.Error(codes.InvalidArgument, "game is required")
+	}
+
+	draw, _, err := fetchLatestDraw(ctx, req.Game)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toProtoDraw(toDrawV1(req.Game, draw)), nil
+}
+
+func (s *stolotoService) ListDraws(req *stolotov1.ListDrawsRequest, stream grpc.ServerStreamingServer[stolotov1.Draw]) error {
+	if req.Game == "" || req.From <= 0 || req.To <= 0 {
+		return status.Error(codes.InvalidArgument, "game and positive from/to are required")
+	}
+	step := int32(1)
+	if req.From > req.To {
+		step = -1
+	}
+	if (req.To-req.From)*step >= maxListDraws {
+		return status.Errorf(codes.InvalidArgument, "range is limited to %d draws", maxListDraws)
+	}
+
+	// Диапазон может быть большим: он не должен вытеснять интерактивные запросы
+	ctx := withPriority(stream.Context(), priorityBulk)
+	for n := req.From; ; n += step {
+		draw, _, err := fetchDraw(ctx, req.Game, int(n))
+		switch {
+		case errors.Is(err, errDrawNotFound):
+			// Пропуски в нумерации и ещё не проведённые тиражи
+		case err != nil:
+			return grpcError(err)
+		default:
+			if err := stream.Send(toProtoDraw(toDrawV1(req.Game, draw))); err != nil {
+				return err
+			}
+		}
+		if n == req.To {
+			return nil
+		}
+	}
+}
+
+func (s *stolotoService) WatchDraws(req *stolotov1.WatchDrawsRequest, stream grpc.ServerStreamingServer[stolotov1.DrawEvent]) error {
+	if !drawEvents.enabled() {
+		return status.Error(codes.Unavailable, "draw events are disabled")
+	}
+	events, unsubscribe := drawEvents.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			if len(req.Games) > 0 && !slices.Contains(req.Games, ev.Game) {
+				continue
+			}
+			if err := stream.Send(toProtoEvent(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *stolotoService) WatchMomentalChanges(_ *stolotov1.WatchMomentalChangesRequest, stream grpc.ServerStreamingServer[stolotov1.MomentalChange]) error {
+	if !momentalChanges.enabled() {
+		return status.Error(codes.Unavailable, "momental change tracking is disabled")
+	}
+	changes, unsubscribe := momentalChanges.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case c, ok := <-changes:
+			if !ok {
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			if err := stream.Send(toProtoMomentalChange(c)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Сообщения строятся из схемы v1, чтобы gRPC и HTTP отдавали одно и то же
+
+func toProtoGame(g GameV1) *stolotov1.Game {
+	game := &stolotov1.Game{Name: g.Name, Title: g.Title, Type: g.Type, SalesOpen: g.SalesOpen}
+	if g.CurrentDraw != nil {
+		game.CurrentDraw = toProtoDraw(*g.CurrentDraw)
+	}
+	if g.LastDraw != nil {
+		game.LastDraw = toProtoDraw(*g.LastDraw)
+	}
+	return game
+}
+
+func toProtoDraw(d DrawV1) *stolotov1.Draw {
+	draw := &stolotov1.Draw{
+		Game:         d.Game,
+		Number:       int32(d.Number),
+		Status:       stolotov1.DrawStatus_DRAW_STATUS_OPEN,
+		SuperPrize:   d.SuperPrize,
+		TotalWinners: int32(d.TotalWinners),
+		TotalPrize:   d.TotalPrize,
+	}
+	if !d.Date.IsZero() {
+		draw.Date = timestamppb.New(d.Date)
+	}
+	if d.Status == "completed" {
+		draw.Status = stolotov1.DrawStatus_DRAW_STATUS_COMPLETED
+	}
+	for _, n := range d.Numbers {
+		draw.Numbers = append(draw.Numbers, int32(n))
+	}
+	for _, p := range d.Prizes {
+		draw.Prizes = append(draw.Prizes, &stolotov1.PrizeCategory{
+			Category: int32(p.Category),
+			Matched:  p.Matched,
+			Winners:  int32(p.Winners),
+			Amount:   p.Amount,
+		})
+	}
+	return draw
+}
+
+func toProtoMomentCard(c MomentCardV1) *stolotov1.MomentCard {
+	return &stolotov1.MomentCard{
+		Id:        c.ID,
+		Name:      c.Name,
+		Price:     c.Price,
+		MaxPrize:  c.MaxPrize,
+		Available: c.Available,
+		ImageUrl:  c.ImageURL,
+	}
+}
+
+func toProtoMomentalChange(c MomentalChangeV1) *stolotov1.MomentalChange {
+	change := &stolotov1.MomentalChange{
+		Time:   timestamppb.New(c.Time),
+		Card:   toProtoMomentCard(c.Card),
+		Fields: c.Fields,
+	}
+	switch c.Type {
+	case momentalAdded:
+		change.Type = stolotov1.MomentalChange_TYPE_ADDED
+	case momentalRemoved:
+		change.Type = stolotov1.MomentalChange_TYPE_REMOVED
+	case momentalChanged:
+		change.Type = stolotov1.MomentalChange_TYPE_CHANGED
+	}
+	if c.Previous != nil {
+		change.Previous = toProtoMomentCard(*c.Previous)
+	}
+	return change
+}
+
+func toProtoEvent(ev drawEvent) *stolotov1.DrawEvent {
+	t := stolotov1.DrawEvent_TYPE_COMPLETED
+	if ev.Type == drawOpened {
+		t = stolotov1.DrawEvent_TYPE_OPENED
+	}
+	return &stolotov1.DrawEvent{Type: t, Draw: toProtoDraw(toDrawV1(ev.Game, ev.Draw))}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"DOUPIG/fakestoloto"
+	stolotov1 "DOUPIG/proto/stoloto/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startGRPC поднимает StolotoService над фейковым Stoloto в памяти и
+// возвращает клиент к нему
+func startGRPC(t *testing.T, configure func(c *Config)) (*fakestoloto.Server, *grpc.ClientConn) {
+	t.Helper()
+
+	fake, _ := startProxy(t, configure)
+
+	lis := bufconn.Listen(1 << 20)
+	rpc := newGRPCServer(nil)
+	go rpc.srv.Serve(lis)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		rpc.shutdown(ctx)
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return fake, conn
+}
+
+// withAPIKeys включает аутентификацию и выпускает ключ на каждый набор областей
+func withAPIKeys(t *testing.T, scopes ...string) []string {
+	t.Helper()
+
+	prev := apiKeys
+	t.Cleanup(func() { apiKeys = prev })
+
+	cfg.Auth.Enabled = true
+	cfg.Auth.KeysFile = filepath.Join(t.TempDir(), "apikeys.json")
+	store, err := newAPIKeyStore(cfg.Auth.KeysFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiKeys = store
+
+	keys := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		plain, _, err := store.create("test "+scope, []string{scope}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, plain)
+	}
+	return keys
+}
+
+func TestGRPCAuth(t *testing.T) {
+	fake, conn := startGRPC(t, nil)
+	keys := withAPIKeys(t, scopeTicketsCheck, scopeDrawsRead)
+	client := stolotov1.NewStolotoServiceClient(conn)
+
+	for _, tc := range []struct {
+		name string
+		key  string
+		want codes.Code
+	}{
+		{"no key", "", codes.Unauthenticated},
+		{"unknown key", "not-a-key", codes.Unauthenticated},
+		{"no scope", keys[0], codes.PermissionDenied},
+		{"draws:read", keys[1], codes.OK},
+	} {
+		ctx := context.Background()
+		if tc.key != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", tc.key)
+		}
+
+		draw, err := client.GetLatestDraw(ctx, &stolotov1.GetLatestDrawRequest{Game: "6x45"})
+		if code := status.Code(err); code != tc.want {
+			t.Fatalf("%s: code = %v, want %v (%v)", tc.name, code, tc.want, err)
+		}
+		if err == nil && int(draw.Number) != fake.CurrentDraw("6x45")-1 {
+			t.Fatalf("%s: latest draw = %d, want %d", tc.name, draw.Number, fake.CurrentDraw("6x45")-1)
+		}
+	}
+
+	// Health открыт без ключа
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || health.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("health = %v, %v; want SERVING", health, err)
+	}
+}
+
+func TestGRPCListDraws(t *testing.T) {
+	fake, conn := startGRPC(t, nil)
+	client := stolotov1.NewStolotoServiceClient(conn)
+	last := int32(fake.CurrentDraw("6x45") - 1)
+
+	stream, err := client.ListDraws(context.Background(), &stolotov1.ListDrawsRequest{Game: "6x45", From: last, To: last - 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int32
+	for {
+		draw, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, draw.Number)
+	}
+	if len(got) != 3 || got[0] != last || got[2] != last-2 {
+		t.Fatalf("draws = %v, want %d..%d newest first", got, last, last-2)
+	}
+
+	for _, req := range []*stolotov1.ListDrawsRequest{
+		{Game: "6x45", From: 1, To: maxListDraws + 1},
+		{Game: "6x45", From: maxListDraws + 1, To: 1},
+		{Game: "6x45", From: 0, To: 10},
+	} {
+		stream, err := client.ListDraws(context.Background(), req)
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if code := status.Code(err); code != codes.InvalidArgument {
+			t.Fatalf("ListDraws %d..%d: code = %v, want InvalidArgument", req.From, req.To, code)
+		}
+	}
+}
+
+func TestGRPCWatchDraws(t *testing.T) {
+	fake, conn := startGRPC(t, func(c *Config) { c.DrawEvents.PollInterval = Duration(10 * time.Millisecond) })
+	client := stolotov1.NewStolotoServiceClient(conn)
+
+	// Свой опрос на тест: глобальный запускается один раз и живёт до остановки
+	prevEvents, prevBackground := drawEvents, background
+	drawEvents, background = &drawWatcher{}, newWorkerGroup()
+	t.Cleanup(func() {
+		background.Stop(context.Background())
+		drawEvents, background = prevEvents, prevBackground
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchDraws(ctx, &stolotov1.WatchDrawsRequest{Games: []string{"7x49"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Первый опрос только запоминает состояние
+	polled := countRequests(fake, "/games/info-new")
+	for countRequests(fake, "/games/info-new") < polled+2 {
+		if ctx.Err() != nil {
+			t.Fatal("draw events are not polling the games list")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	number := fake.CurrentDraw("7x49")
+	if err := fake.CompleteDraw("7x49"); err != nil {
+		t.Fatal(err)
+	}
+
+	var completed, opened bool
+	for !completed || !opened {
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("WatchDraws: %v", err)
+		}
+		if ev.Draw.Game != "7x49" {
+			t.Fatalf("event for %s, want only 7x49", ev.Draw.Game)
+		}
+		switch ev.Type {
+		case stolotov1.DrawEvent_TYPE_COMPLETED:
+			completed = int(ev.Draw.Number) == number
+		case stolotov1.DrawEvent_TYPE_OPENED:
+			opened = int(ev.Draw.Number) == number+1
+		}
+	}
+}
+
+func TestGRPCWatchDrawsDisabled(t *testing.T) {
+	_, conn := startGRPC(t, func(c *Config) { c.DrawEvents.PollInterval = 0 })
+
+	stream, err := stolotov1.NewStolotoServiceClient(conn).WatchDraws(context.Background(), &stolotov1.WatchDrawsRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if code := status.Code(err); code != codes.Unavailable {
+		t.Fatalf("code = %v, want Unavailable", code)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// HeaderPolicyConfig — какие заголовки ответа stoloto.ru передаются клиентам.
+// Если Allow не пуст, передаются только перечисленные; Deny удаляет заголовки
+// в любом случае. Шаблон "X-*" совпадает со всеми заголовками с этим префиксом.
+type HeaderPolicyConfig struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+	// Via — имя прокси в заголовке Via, например "stoloto-proxy"
+	Via string `json:"via"`
+}
+
+// hopByHopHeaders относятся к одному соединению и не пересылаются (RFC 9110, 7.6.1)
+var hopByHopHeaders = []string{"Connection", "Keep-Alive", "Proxy-Connection", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade"}
+
+// alwaysStripped не пересылаются независимо от конфигурации: куки stoloto.ru
+// не предназначены нашим клиентам, CORS и кеширование решает прокси,
+// а длина и кодировка тела к моменту отдачи уже другие
+var alwaysStripped = []string{"Set-Cookie", "Set-Cookie2", "Access-Control-*", "Content-Encoding", "Content-Length"}
+
+// proxyMarkers выставляет сам прокси (см. markStale); фильтр их не трогает
+var proxyMarkers = []string{"Warning", "X-Data-Stale", "Age"}
+
+func headerMatches(patterns []string, name string) bool {
+	for _, p := range patterns {
+		p = textproto.CanonicalMIMEHeaderKey(p)
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// copyUpstreamHeaders переносит в dst заголовки ответа апстрима по политике
+// и добавляет наш Via
+func copyUpstreamHeaders(dst, src http.Header, policy HeaderPolicyConfig) {
+	// Заголовки, перечисленные в Connection, тоже hop-by-hop
+	connection := hopByHopHeaders
+	for _, v := range src.Values("Connection") {
+		for _, name := range strings.Split(v, ",") {
+			connection = append(connection, strings.TrimSpace(name))
+		}
+	}
+
+	for name, values := range src {
+		switch {
+		case headerMatches(proxyMarkers, name):
+		case name == "Via":
+		case headerMatches(connection, name), headerMatches(alwaysStripped, name), headerMatches(validatorHeaders, name):
+			continue
+		case headerMatches(policy.Deny, name):
+			continue
+		case len(policy.Allow) > 0 && !headerMatches(policy.Allow, name):
+			continue
+		}
+
+		for _, v := range values {
+			dst.Add(name, v)
+		}
+	}
+
+	if policy.Via != "" {
+		dst.Add("Via", "1.1 "+policy.Via)
+	}
+}
+
+// upstreamStatus — код для клиента вместо 5xx апстрима: 504, если апстрим
+// сам сообщил о таймауте, иначе 502
+func upstreamStatus(code int) int {
+	if code == http.StatusGatewayTimeout {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// upstreamErrorStatus — код для ошибки запроса к апстриму: таймаут — 504, остальное — 502
+func upstreamErrorStatus(err error) int {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return http.StatusGatewayTimeout
+	}
+	if errors.Is(err, errUpstreamQueueFull) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestCopyUpstreamHeaders(t *testing.T) {
+	src := http.Header{
+		"Content-Type":                {"application/json"},
+		"Content-Language":            {"ru"},
+		"Content-Length":              {"42"},
+		"Content-Encoding":            {"gzip"},
+		"Connection":                  {"keep-alive, X-Trace"},
+		"Keep-Alive":                  {"timeout=5"},
+		"Transfer-Encoding":           {"chunked"},
+		"Set-Cookie":                  {"session=secret"},
+		"Access-Control-Allow-Origin": {"*"},
+		"Cache-Control":               {"no-cache"},
+		"Etag":                        {`"upstream"`},
+		"Server":                      {"nginx"},
+		"X-Request-Id":                {"abc"},
+		"Strict-Transport-Security":   {"max-age=31536000"},
+		"Via":                         {"1.1 cdn"},
+		"Warning":                     {`110 - "Response is Stale"`},
+		"X-Data-Stale":                {"true"},
+	}
+
+	t.Run("default policy", func(t *testing.T) {
+		dst := http.Header{}
+		copyUpstreamHeaders(dst, src, defaultConfig().Headers)
+
+		want := []string{"Content-Language", "Content-Type", "Via", "Warning", "X-Data-Stale"}
+		var got []string
+		for name := range dst {
+			got = append(got, name)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Fatalf("copied %v, want %v", got, want)
+		}
+		if via := dst.Values("Via"); !slices.Equal(via, []string{"1.1 cdn", "1.1 stoloto-proxy"}) {
+			t.Fatalf("Via = %q", via)
+		}
+	})
+
+	t.Run("no allowlist", func(t *testing.T) {
+		dst := http.Header{}
+		copyUpstreamHeaders(dst, src, HeaderPolicyConfig{Deny: []string{"server"}})
+
+		for _, name := range []string{"Strict-Transport-Security", "X-Request-Id"} {
+			if dst.Get(name) == "" {
+				t.Errorf("%s was not copied", name)
+			}
+		}
+		// Эти не проходят ни при какой политике
+		for _, name := range []string{"Server", "Connection", "Keep-Alive", "Transfer-Encoding", "X-Trace",
+			"Set-Cookie", "Access-Control-Allow-Origin", "Content-Length", "Content-Encoding", "Cache-Control", "Etag"} {
+			if dst.Get(name) != "" {
+				t.Errorf("%s was copied", name)
+			}
+		}
+		if via := dst.Values("Via"); !slices.Equal(via, []string{"1.1 cdn"}) {
+			t.Errorf("Via without a proxy name = %q", via)
+		}
+	})
+
+	t.Run("connection-listed header", func(t *testing.T) {
+		dst := http.Header{}
+		copyUpstreamHeaders(dst, http.Header{"Connection": {"X-Trace"}, "X-Trace": {"1"}, "X-Other": {"2"}}, HeaderPolicyConfig{})
+		if dst.Get("X-Trace") != "" || dst.Get("X-Other") != "2" {
+			t.Fatalf("headers %v", dst)
+		}
+	})
+}
+
+func TestHeaderMatches(t *testing.T) {
+	patterns := []string{"content-type", "X-*"}
+	for name, want := range map[string]bool{
+		"Content-Type":   true,
+		"Content-Length": false,
+		"X-Request-Id":   true,
+		"Xray":           false,
+	} {
+		if got := headerMatches(patterns, name); got != want {
+			t.Errorf("headerMatches(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestUpstreamStatus(t *testing.T) {
+	for code, want := range map[int]int{
+		http.StatusInternalServerError: http.StatusBadGateway,
+		http.StatusBadGateway:          http.StatusBadGateway,
+		http.StatusServiceUnavailable:  http.StatusBadGateway,
+		http.StatusGatewayTimeout:      http.StatusGatewayTimeout,
+	} {
+		if got := upstreamStatus(code); got != want {
+			t.Errorf("upstreamStatus(%d) = %d, want %d", code, got, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("error making request: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{os.ErrDeadlineExceeded, http.StatusGatewayTimeout},
+		{fmt.Errorf("wrapped: %w", errUpstreamQueueFull), http.StatusServiceUnavailable},
+		{errors.New("connection refused"), http.StatusBadGateway},
+	} {
+		if got := upstreamErrorStatus(tc.err); got != tc.want {
+			t.Errorf("upstreamErrorStatus(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestProxyVia(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+
+	resp := getJSON(t, proxy.URL+"/api/raw/games/6x45/draws/"+strconv.Itoa(fake.CurrentDraw("6x45")-1), nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Via") != "1.1 stoloto-proxy" {
+		t.Fatalf("status %d, Via %q", resp.StatusCode, resp.Header.Get("Via"))
+	}
+}
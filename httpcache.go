@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPCacheConfig — сколько клиентам и CDN разрешено кешировать ответы прокси.
+// Для списка игр и последнего тиража max-age дополнительно ограничивается
+// временем до ближайшего тиража, чтобы кеш не пережил его результаты.
+type HTTPCacheConfig struct {
+	GamesMaxAge     Duration `json:"gamesMaxAge"`
+	DrawMaxAge      Duration `json:"drawMaxAge"`
+	MomentalMaxAge  Duration `json:"momentalMaxAge"`
+	CompletedMaxAge Duration `json:"completedMaxAge"`
+}
+
+// cacheHints — политика кеширования конкретного ответа
+type cacheHints struct {
+	maxAge       time.Duration
+	immutable    bool
+	lastModified time.Time
+}
+
+// cacheHintsFunc вычисляет политику по телу успешного ответа
+type cacheHintsFunc func(body []byte, now time.Time) cacheHints
+
+// validatorHeaders апстрима заменяются нашими
+var validatorHeaders = []string{"Cache-Control", "Expires", "ETag", "Last-Modified", "Pragma", "Age"}
+
+func setCacheHeaders(h http.Header, hints cacheHints, now time.Time) {
+	switch {
+	case hints.immutable:
+		h.Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(hints.maxAge.Seconds())))
+	case hints.maxAge > 0:
+		h.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(hints.maxAge.Seconds())))
+	default:
+		h.Set("Cache-Control", "no-cache")
+	}
+	h.Set("Expires", now.Add(hints.maxAge).UTC().Format(http.TimeFormat))
+}
+
+// strongETag — хеш тела ответа
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// untilNext ограничивает maxAge временем до момента next, если он в будущем
+func untilNext(maxAge time.Duration, next, now time.Time) time.Duration {
+	if next.IsZero() || !next.After(now) {
+		return maxAge
+	}
+	return min(maxAge, next.Sub(now).Truncate(time.Second))
+}
+
+// upstreamTime разбирает дату Stoloto: RFC 3339, время без зоны (московское)
+// или миллисекунды Unix
+func upstreamTime(raw json.RawMessage) (time.Time, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}, false
+	}
+
+	var ms int64
+	if err := json.Unmarshal(raw, &ms); err == nil {
+		return time.UnixMilli(ms), true
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", s, moscow); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// moscow — тиражи Stoloto проводятся по московскому времени
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+// drawCacheHints: завершённый тираж не меняется, активный — до момента проведения
+func drawCacheHints(body []byte, now time.Time) cacheHints {
+	var doc upstreamDrawResponse
+	if err := json.Unmarshal(body, &doc); err != nil || doc.Draw == nil {
+		return cacheHints{}
+	}
+	return drawHints(doc.Draw, now)
+}
+
+func drawHints(d *upstreamDraw, now time.Time) cacheHints {
+	if d.completed() {
+		return cacheHints{maxAge: time.Duration(cfg.HTTPCache.CompletedMaxAge), immutable: true, lastModified: d.Date.Time}
+	}
+	return cacheHints{maxAge: untilNext(time.Duration(cfg.HTTPCache.DrawMaxAge), d.Date.Time, now)}
+}
+
+// gamesCacheHints: список меняется с каждым тиражом любой игры
+func gamesCacheHints(body []byte, now time.Time) cacheHints {
+	var doc upstreamGamesResponse
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return cacheHints{}
+	}
+	return gamesHints(&doc, now)
+}
+
+func gamesHints(doc *upstreamGamesResponse, now time.Time) cacheHints {
+	var next, lastModified time.Time
+	for _, g := range doc.Games {
+		if g.Draw != nil {
+			if t := g.Draw.Date.Time; t.After(now) && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+		if g.CompletedDraw != nil {
+			if t := g.CompletedDraw.Date.Time; !t.After(now) && t.After(lastModified) {
+				lastModified = t
+			}
+		}
+	}
+
+	return cacheHints{
+		maxAge:       untilNext(time.Duration(cfg.HTTPCache.GamesMaxAge), next, now),
+		lastModified: lastModified,
+	}
+}
+
+func momentalCacheHints([]byte, time.Time) cacheHints {
+	return cacheHints{maxAge: time.Duration(cfg.HTTPCache.MomentalMaxAge)}
+}
+
+// staleCacheHints: данные, отданные из-за сбоя апстрима, не должны застревать в CDN
+func staleCacheHints(h cacheHints) cacheHints {
+	return cacheHints{lastModified: h.lastModified}
+}
+
+// serveBody отдаёт успешный ответ с ETag и заголовками кеширования;
+// условные запросы получают 304. Данные, отданные из-за сбоя апстрима
+// (upstream с X-Data-Stale), помечаются и не кешируются.
+func serveBody(w http.ResponseWriter, r *http.Request, body []byte, h cacheHints, upstream http.Header) {
+	now := time.Now()
+	if upstream != nil && upstream.Get("X-Data-Stale") != "" {
+		h = staleCacheHints(h)
+		for _, name := range proxyMarkers {
+			if v := upstream.Get(name); v != "" {
+				w.Header().Set(name, v)
+			}
+		}
+	}
+
+	setCacheHeaders(w.Header(), h, now)
+	w.Header().Set("ETag", strongETag(body))
+	w.Header().Del("Content-Length")
+	http.ServeContent(w, r, "", h.lastModified, bytes.NewReader(body))
+}
+
+// ageSeconds — значение заголовка Age ответа апстрима или кеша
+func ageSeconds(h http.Header) int {
+	age, _ := strconv.Atoi(strings.TrimSpace(h.Get("Age")))
+	return age
+}
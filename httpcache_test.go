@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestUntilNext(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		next time.Time
+		want time.Duration
+	}{
+		{time.Time{}, time.Minute},
+		{now.Add(-time.Second), time.Minute},
+		{now, time.Minute},
+		{now.Add(20*time.Second + 500*time.Millisecond), 20 * time.Second},
+		{now.Add(time.Hour), time.Minute},
+	} {
+		if got := untilNext(time.Minute, tc.next, now); got != tc.want {
+			t.Errorf("untilNext(%v) = %v, want %v", tc.next.Sub(now), got, tc.want)
+		}
+	}
+}
+
+func TestUpstreamTime(t *testing.T) {
+	want := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for _, raw := range []string{
+		`"2026-10-01T12:00:00+03:00"`,
+		`"2026-10-01T09:00:00Z"`,
+		// Без зоны — московское время
+		`"2026-10-01T12:00:00"`,
+		strconv.FormatInt(want.UnixMilli(), 10),
+	} {
+		got, ok := upstreamTime(json.RawMessage(raw))
+		if !ok || !got.Equal(want) {
+			t.Errorf("upstreamTime(%s) = %v, %v; want %v", raw, got, ok, want)
+		}
+	}
+
+	for _, raw := range []string{``, `null`, `"tomorrow"`, `{}`} {
+		if got, ok := upstreamTime(json.RawMessage(raw)); ok {
+			t.Errorf("upstreamTime(%q) = %v, want no time", raw, got)
+		}
+	}
+}
+
+var maxAgePattern = regexp.MustCompile(`max-age=(\d+)`)
+
+func maxAge(t *testing.T, h http.Header) int {
+	t.Helper()
+
+	m := maxAgePattern.FindStringSubmatch(h.Get("Cache-Control"))
+	if m == nil {
+		t.Fatalf("Cache-Control %q has no max-age", h.Get("Cache-Control"))
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+func TestProxyCacheHeaders(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+	current := fake.CurrentDraw("6x45")
+
+	// Проведённый тираж не меняется
+	url := proxy.URL + "/api/v1/games/6x45/draws/" + strconv.Itoa(current-1)
+	resp := getJSON(t, url, nil)
+	etag := resp.Header.Get("ETag")
+	if cc := resp.Header.Get("Cache-Control"); cc != "public, max-age=31536000, immutable" {
+		t.Fatalf("completed draw Cache-Control = %q", cc)
+	}
+	if etag == "" || resp.Header.Get("Last-Modified") == "" || resp.Header.Get("Expires") == "" {
+		t.Fatalf("completed draw validators: %v", resp.Header)
+	}
+	if again := getJSON(t, url, nil).Header.Get("ETag"); again != etag {
+		t.Fatalf("ETag changed between identical responses: %s, then %s", etag, again)
+	}
+
+	// Условный запрос с тем же ETag получает 304 без тела
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified || resp.ContentLength > 0 {
+		t.Fatalf("If-None-Match: status %d, length %d; want 304 without body", resp.StatusCode, resp.ContentLength)
+	}
+
+	// Открытый тираж и список игр кешируются ненадолго
+	resp = getJSON(t, proxy.URL+"/api/v1/games/6x45/draws/"+strconv.Itoa(current), nil)
+	if age := maxAge(t, resp.Header); age <= 0 || age > 60 {
+		t.Fatalf("open draw max-age = %d, want 1..60", age)
+	}
+	resp = getJSON(t, proxy.URL+"/api/v1/games", nil)
+	if age := maxAge(t, resp.Header); age <= 0 || age > 30 {
+		t.Fatalf("games max-age = %d, want 1..30", age)
+	}
+}
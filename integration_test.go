@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"DOUPIG/fakestoloto"
+)
+
+// startProxy поднимает прокси над фейковым Stoloto. Конфигурация — по
+// умолчанию, но без лимитов и SWR, с быстрыми повторами; configure может её
+// поменять до регистрации маршрутов. Тесты меняют глобальное состояние,
+// поэтому их нельзя запускать параллельно.
+func startProxy(t *testing.T, configure func(c *Config)) (*fakestoloto.Server, *httptest.Server) {
+	t.Helper()
+
+	fake := fakestoloto.NewServer()
+	t.Cleanup(fake.Close)
+
+	prevCfg, prevCache, prevBudget := cfg, upstreamCache, upstreamBudget
+	t.Cleanup(func() { cfg, upstreamCache, upstreamBudget = prevCfg, prevCache, prevBudget })
+
+	cfg = defaultConfig()
+	cfg.Upstream.BaseURL = fake.BaseURL()
+	cfg.Upstream.MomentalURL = fake.MomentalURL()
+	cfg.RateLimit.Enabled = false
+	cfg.SWR = SWRConfig{}
+	cfg.Retry.Default.BaseDelay = Duration(time.Millisecond)
+	cfg.Retry.Default.MaxDelay = Duration(5 * time.Millisecond)
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	upstreamCache = newResponseCache(cfg.Stale)
+	upstreamBudget = newOutboundLimiter(cfg.UpstreamBudget)
+
+	mux := http.NewServeMux()
+	registerRoutes(mux)
+	proxy := httptest.NewServer(newProxyHandler(mux))
+	t.Cleanup(proxy.Close)
+	return fake, proxy
+}
+
+// getJSON выполняет GET и разбирает тело в v, если v не nil
+func getJSON(t *testing.T, url string, v any) *http.Response {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s: reading body: %v", url, err)
+	}
+	if v != nil && resp.StatusCode == http.StatusOK {
+		if err := json.Unmarshal(body, v); err != nil {
+			t.Fatalf("GET %s: parsing %s: %v", url, body, err)
+		}
+	}
+	return resp
+}
+
+func TestProxyDrawCompletes(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+	current := fake.CurrentDraw("6x45")
+
+	var draw DrawV1
+	if resp := getJSON(t, proxy.URL+"/api/v1/games/6x45/draws/latest", &draw); resp.StatusCode != http.StatusOK {
+		t.Fatalf("latest draw: status %d", resp.StatusCode)
+	}
+	if draw.Number != current-1 || draw.Status != "completed" {
+		t.Fatalf("latest draw = %d %s, want %d completed", draw.Number, draw.Status, current-1)
+	}
+
+	if err := fake.CompleteDraw("6x45"); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp := getJSON(t, proxy.URL+"/api/v1/games/6x45/draws/latest", &draw); resp.StatusCode != http.StatusOK {
+		t.Fatalf("latest draw after completion: status %d", resp.StatusCode)
+	}
+	if draw.Number != current {
+		t.Fatalf("latest draw after completion = %d, want %d", draw.Number, current)
+	}
+	if len(draw.Numbers) != 6 {
+		t.Fatalf("completed draw numbers = %v, want 6 numbers", draw.Numbers)
+	}
+}
+
+func TestProxyUpstreamFaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		fault  fakestoloto.Fault
+		status int
+	}{
+		{"5xx", fakestoloto.Fault{Status: http.StatusServiceUnavailable}, http.StatusBadGateway},
+		{"gateway timeout", fakestoloto.Fault{Status: http.StatusGatewayTimeout}, http.StatusGatewayTimeout},
+		{"slow response", fakestoloto.Fault{Delay: 2 * time.Second}, http.StatusGatewayTimeout},
+		{"malformed JSON", fakestoloto.Fault{Malformed: true}, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, proxy := startProxy(t, func(c *Config) {
+				c.Retry.Default.Budget = Duration(200 * time.Millisecond)
+			})
+			fake.InjectFault(tt.fault)
+
+			resp := getJSON(t, proxy.URL+"/api/v1/games", nil)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
@@ -1,20 +1,23 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
-	_ "DOUPIG/docs" // важно: замените на ваш путь
-
-	httpSwagger "github.com/swaggo/http-swagger"
+	"DOUPIG/docs" // важно: замените на ваш путь
 )
 
 const (
-	baseURL      = "https://www.stoloto.ru/p/api/mobile/api/v35"
 	partnerToken = "bXMjXFRXZ3coWXh6R3s1NTdUX3dnWlBMLUxmdg"
 	userAgent    = "Mozilla/5.0 (iPhone; CPU iPhone OS 14_0 like Mac OS X) AppleWebKit/605.1.15"
 )
@@ -26,21 +29,106 @@ const (
 // @description Прокси-сервер для API Stoloto
 // @host localhost:8080
 // @BasePath /
+// @schemes http https
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API-ключ; можно также передать параметром запроса api_key
 
 func main() {
-	// Регистрируем handlers
-	http.HandleFunc("/api/draws/", handleDraws)
-	http.HandleFunc("/api/draw/", handleDraw)
-	http.HandleFunc("/api/draw/latest", handleDrawLatest)
-	http.HandleFunc("/api/draw/prelatest", handleDrawPreLatest)
-	http.HandleFunc("/api/draw/momental", handleMomentalCards)
-
-	// Swagger UI
-	http.Handle("/swagger/", httpSwagger.WrapHandler)
-
-	log.Println("Server starting on :8080")
-	log.Println("Swagger UI available at http://localhost:8080/swagger/index.html")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		if err := runAPIKeyCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if runCLI(os.Args[1:]) {
+		return
+	}
+
+	configPath := flag.String("config", "", "путь к JSON-файлу конфигурации")
+	record := flag.String("record", "", "записывать обмены с апстримом в кассету")
+	replay := flag.String("replay", "", "отвечать из кассеты без обращения к апстриму")
+	flag.Parse()
+
+	var err error
+	cfg, err = loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch {
+	case *record != "":
+		cfg.Cassette = CassetteConfig{Mode: "record", File: *record}
+	case *replay != "":
+		cfg.Cassette = CassetteConfig{Mode: "replay", File: *replay}
+	}
+	upstreamCache = newResponseCache(cfg.Stale)
+
+	if err := upstreamCassette.configure(cfg.Cassette); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Cassette.Mode != "" {
+		log.Printf("Upstream cassette: %s %s", cfg.Cassette.Mode, cfg.Cassette.File)
+	}
+
+	upstreamBudget = newOutboundLimiter(cfg.UpstreamBudget)
+
+	apiKeys, err = newAPIKeyStore(cfg.Auth.KeysFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	registerRoutes(http.DefaultServeMux)
+
+	// Swagger UI должен обращаться к API по той же схеме и хосту, что и клиенты
+	docs.SwaggerInfo.Host = publicHost()
+	docs.SwaggerInfo.Schemes = []string{publicScheme()}
+
+	srv := newHTTPServer(newProxyHandler(http.DefaultServeMux))
+	servers := []*http.Server{srv}
+
+	var tlsConfig *tls.Config
+	if cfg.TLS.enabled() {
+		certs, err := newCertReloader(cfg.TLS)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tlsConfig = certs.tlsConfig()
+		srv.TLSConfig = tlsConfig
+
+		if cfg.TLS.RedirectAddr != "" {
+			servers = append(servers, newRedirectServer(cfg.TLS.RedirectAddr, srv.Addr))
+			log.Printf("Redirecting HTTP on %s to HTTPS", cfg.TLS.RedirectAddr)
+		}
+	}
+
+	var rpc *grpcServer
+	if cfg.GRPC.Addr != "" {
+		rpc = newGRPCServer(tlsConfig)
+		log.Printf("gRPC server starting on %s", cfg.GRPC.Addr)
+	}
+
+	if cfg.SWR.Warmup {
+		warmupSWR()
+	}
+	if cfg.MomentalChanges.PollInterval > 0 {
+		if err := momentalChanges.start(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("Server starting on %s (%s)", srv.Addr, publicScheme())
+	log.Printf("Swagger UI available at %s/swagger/index.html", publicBaseURL())
+	if err := serve(rpc, servers...); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// newProxyHandler оборачивает маршруты в middleware прокси
+func newProxyHandler(mux *http.ServeMux) http.Handler {
+	return withCompression(withCORS(withAuth(withRateLimit(withRouteErrors(mux)))))
 }
 
 // GetDraws godoc
@@ -48,23 +136,19 @@ func main() {
 // @Description Возвращает информацию о всех доступных играх
 // @Tags draws
 // @Produce json
+// @Security ApiKeyAuth
 // @Success 200 {object} map[string]interface{} "Успешный ответ"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
-// @Router /api/draws/ [get]
+// @Router /api/raw/games [get]
 func handleDraws(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	resp, err := handleDrawsHandle()
+	resp, err := handleDrawsHandle(r.Context())
 	if err != nil {
-		sendError(w, err.Error(), http.StatusInternalServerError)
+		sendError(w, err.Error(), upstreamErrorStatus(err))
 		return
 	}
 	defer resp.Body.Close()
 
-	forwardResponse(w, resp)
+	forwardResponse(w, r, resp, gamesCacheHints)
 }
 
 // GetDraws godoc
@@ -72,23 +156,28 @@ func handleDraws(w http.ResponseWriter, r *http.Request) {
 // @Description Возвращает информацию о всех моментальных играх
 // @Tags draws
 // @Produce json
+// @Security ApiKeyAuth
+// @Param platform query string false "Платформа витрины CMS" default(OS)
+// @Param userSegment query string false "Сегмент пользователей витрины CMS" default(ALL)
 // @Success 200 {object} map[string]interface{} "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
-// @Router /api/draw/momental [get]
+// @Router /api/raw/momental [get]
 func handleMomentalCards(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	opts, err := parseMomentalOptions(r.URL.Query())
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	resp, err := handleMomentalHandle()
+	resp, err := handleMomentalHandle(r.Context(), opts)
 	if err != nil {
-		sendError(w, err.Error(), http.StatusInternalServerError)
+		sendError(w, err.Error(), upstreamErrorStatus(err))
 		return
 	}
 	defer resp.Body.Close()
 
-	forwardResponse(w, resp)
+	forwardResponse(w, r, resp, momentalCacheHints)
 }
 
 // GetDraw godoc
@@ -96,31 +185,29 @@ func handleMomentalCards(w http.ResponseWriter, r *http.Request) {
 // @Description Возвращает данные о конкретном розыгрыше по имени игры и номеру
 // @Tags draws
 // @Produce json
-// @Param name query string true "Название игры (например: 5x36, 6x45)"
-// @Param number query string true "Номер розыгрыша"
+// @Security ApiKeyAuth
+// @Param name path string true "Название игры (например: 5x36plus, 6x45)"
+// @Param number path string true "Номер розыгрыша"
 // @Success 200 {object} map[string]interface{} "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверные параметры"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
-// @Router /api/draw/ [get]
+// @Router /api/raw/games/{name}/draws/{number} [get]
 func handleDraw(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	name := pathParam(r, "name")
+	number := pathParam(r, "number")
+
+	resp, err := handleDrawHandle(r.Context(), name, number)
+	if errors.Is(err, errMissingDrawParams) {
+		sendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// Извлекаем параметры из query string
-	query := r.URL.Query()
-	name := query.Get("name")
-	number := query.Get("number")
-
-	resp, err := handleDrawHandle(name, number)
 	if err != nil {
-		sendError(w, err.Error(), http.StatusBadRequest)
+		sendError(w, err.Error(), upstreamErrorStatus(err))
 		return
 	}
 	defer resp.Body.Close()
 
-	forwardResponse(w, resp)
+	forwardResponse(w, r, resp, drawCacheHints)
 }
 
 // GetPreLatestDraw godoc
@@ -128,19 +215,15 @@ func handleDraw(w http.ResponseWriter, r *http.Request) {
 // @Description Возвращает данные последнего розыгрыша для указанной игры
 // @Tags draws
 // @Produce json
-// @Param name query string true "Название игры (например: 5x36, 6x45)"
+// @Security ApiKeyAuth
+// @Param name path string true "Название игры (например: 5x36plus, 6x45)"
 // @Success 200 {object} map[string]interface{} "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Не указано имя игры"
 // @Failure 404 {object} ErrorResponse "Игра не найдена"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
-// @Router /api/draw/prelatest [get]
+// @Router /api/raw/games/{name}/draws/prelatest [get]
 func handleDrawPreLatest(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	name := r.URL.Query().Get("name")
+	name := pathParam(r, "name")
 	if name == "" {
 		sendError(w, `Missing required parameter: "name"`, http.StatusBadRequest)
 		return
@@ -149,13 +232,18 @@ func handleDrawPreLatest(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Searching for latest draw of game: %s", name)
 
 	// 1. Получаем список игр через handleDrawsHandle
-	gamesResp, err := handleDrawsHandle()
+	gamesResp, err := handleDrawsHandle(r.Context())
 	if err != nil {
-		sendError(w, "Error fetching games list: "+err.Error(), http.StatusInternalServerError)
+		sendError(w, "Error fetching games list: "+err.Error(), upstreamErrorStatus(err))
 		return
 	}
 	defer gamesResp.Body.Close()
 
+	if gamesResp.StatusCode >= 500 {
+		sendError(w, "Error fetching games list: upstream returned "+gamesResp.Status, upstreamStatus(gamesResp.StatusCode))
+		return
+	}
+
 	// 2. Читаем весь ответ
 	body, err := io.ReadAll(gamesResp.Body)
 	if err != nil {
@@ -167,7 +255,7 @@ func handleDrawPreLatest(w http.ResponseWriter, r *http.Request) {
 	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
 		log.Printf("JSON parse error: %v", err)
-		sendError(w, "Error parsing JSON response: "+err.Error(), http.StatusInternalServerError)
+		sendError(w, "Error parsing JSON response: "+err.Error(), http.StatusBadGateway)
 		return
 	}
 
@@ -175,7 +263,7 @@ func handleDrawPreLatest(w http.ResponseWriter, r *http.Request) {
 	requestStatus, ok := result["requestStatus"].(string)
 	if !ok || requestStatus != "success" {
 		log.Printf("API returned requestStatus not 'success': %v", result)
-		sendError(w, "External API returned error", http.StatusInternalServerError)
+		sendError(w, "External API returned error", http.StatusBadGateway)
 		return
 	}
 
@@ -287,15 +375,16 @@ func handleDrawPreLatest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 8. Получаем данные розыгрыша через handleDrawHandle
-	drawResp, err := handleDrawHandle(name, fmt.Sprintf("%d", latestNumber-1))
+	drawResp, err := handleDrawHandle(r.Context(), name, fmt.Sprintf("%d", latestNumber-1))
 	if err != nil {
-		sendError(w, "Error fetching draw data: "+err.Error(), http.StatusInternalServerError)
+		sendError(w, "Error fetching draw data: "+err.Error(), upstreamErrorStatus(err))
 		return
 	}
 	defer drawResp.Body.Close()
 
-	// 9. Возвращаем данные розыгрыша
-	forwardResponse(w, drawResp)
+	// 9. Возвращаем данные розыгрыша; если список игр был из кеша, помечаем и ответ
+	inheritStale(drawResp, gamesResp)
+	forwardResponse(w, r, drawResp, drawCacheHints)
 }
 
 // GetLatestDraw godoc
@@ -303,19 +392,15 @@ func handleDrawPreLatest(w http.ResponseWriter, r *http.Request) {
 // @Description Возвращает данные последнего розыгрыша для указанной игры
 // @Tags draws
 // @Produce json
-// @Param name query string true "Название игры (например: 5x36, 6x45)"
+// @Security ApiKeyAuth
+// @Param name path string true "Название игры (например: 5x36plus, 6x45)"
 // @Success 200 {object} map[string]interface{} "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Не указано имя игры"
 // @Failure 404 {object} ErrorResponse "Игра не найдена"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
-// @Router /api/draw/latest [get]
+// @Router /api/raw/games/{name}/draws/latest [get]
 func handleDrawLatest(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	name := r.URL.Query().Get("name")
+	name := pathParam(r, "name")
 	if name == "" {
 		sendError(w, `Missing required parameter: "name"`, http.StatusBadRequest)
 		return
@@ -324,13 +409,18 @@ func handleDrawLatest(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Searching for latest draw of game: %s", name)
 
 	// 1. Получаем список игр через handleDrawsHandle
-	gamesResp, err := handleDrawsHandle()
+	gamesResp, err := handleDrawsHandle(r.Context())
 	if err != nil {
-		sendError(w, "Error fetching games list: "+err.Error(), http.StatusInternalServerError)
+		sendError(w, "Error fetching games list: "+err.Error(), upstreamErrorStatus(err))
 		return
 	}
 	defer gamesResp.Body.Close()
 
+	if gamesResp.StatusCode >= 500 {
+		sendError(w, "Error fetching games list: upstream returned "+gamesResp.Status, upstreamStatus(gamesResp.StatusCode))
+		return
+	}
+
 	// 2. Читаем весь ответ
 	body, err := io.ReadAll(gamesResp.Body)
 	if err != nil {
@@ -342,7 +432,7 @@ func handleDrawLatest(w http.ResponseWriter, r *http.Request) {
 	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
 		log.Printf("JSON parse error: %v", err)
-		sendError(w, "Error parsing JSON response: "+err.Error(), http.StatusInternalServerError)
+		sendError(w, "Error parsing JSON response: "+err.Error(), http.StatusBadGateway)
 		return
 	}
 
@@ -350,7 +440,7 @@ func handleDrawLatest(w http.ResponseWriter, r *http.Request) {
 	requestStatus, ok := result["requestStatus"].(string)
 	if !ok || requestStatus != "success" {
 		log.Printf("API returned requestStatus not 'success': %v", result)
-		sendError(w, "External API returned error", http.StatusInternalServerError)
+		sendError(w, "External API returned error", http.StatusBadGateway)
 		return
 	}
 
@@ -462,15 +552,16 @@ func handleDrawLatest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 8. Получаем данные розыгрыша через handleDrawHandle
-	drawResp, err := handleDrawHandle(name, fmt.Sprintf("%d", latestNumber))
+	drawResp, err := handleDrawHandle(r.Context(), name, fmt.Sprintf("%d", latestNumber))
 	if err != nil {
-		sendError(w, "Error fetching draw data: "+err.Error(), http.StatusInternalServerError)
+		sendError(w, "Error fetching draw data: "+err.Error(), upstreamErrorStatus(err))
 		return
 	}
 	defer drawResp.Body.Close()
 
-	// 9. Возвращаем данные розыгрыша
-	forwardResponse(w, drawResp)
+	// 9. Возвращаем данные розыгрыша; если список игр был из кеша, помечаем и ответ
+	inheritStale(drawResp, gamesResp)
+	forwardResponse(w, r, drawResp, drawCacheHints)
 }
 
 // ErrorResponse represents an error response
@@ -479,14 +570,16 @@ type ErrorResponse struct {
 	Success bool   `json:"success"`
 }
 
+var errMissingDrawParams = errors.New(`missing required parameters: "name" and "number"`)
+
 // ФУНКЦИЯ ДЛЯ API - принимает name и number
-func handleDrawHandle(name, number string) (*http.Response, error) {
+func handleDrawHandle(ctx context.Context, name, number string) (*http.Response, error) {
 	if name == "" || number == "" {
-		return nil, fmt.Errorf(`missing required parameters: "name" and "number"`)
+		return nil, errMissingDrawParams
 	}
 
 	url := fmt.Sprintf("/service/draws/%s/%s", name, number)
-	resp, err := makeAPIRequest(url)
+	resp, err := makeAPIRequest(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("error making API request: %w", err)
 	}
@@ -495,8 +588,8 @@ func handleDrawHandle(name, number string) (*http.Response, error) {
 }
 
 // ФУНКЦИЯ ДЛЯ API - ничего не принимает
-func handleDrawsHandle() (*http.Response, error) {
-	resp, err := makeAPIRequest("/service/games/info-new")
+func handleDrawsHandle(ctx context.Context) (*http.Response, error) {
+	resp, err := makeAPIRequest(ctx, "/service/games/info-new")
 	if err != nil {
 		return nil, fmt.Errorf("error making API request: %w", err)
 	}
@@ -504,8 +597,8 @@ func handleDrawsHandle() (*http.Response, error) {
 	return resp, nil
 }
 
-func handleMomentalHandle() (*http.Response, error) {
-	resp, err := makeMomentalrequest("https://api.stoloto.ru/cms/api/moment-cards-section?platform=OS&user-segment=ALL")
+func handleMomentalHandle(ctx context.Context, opts momentalOptions) (*http.Response, error) {
+	resp, err := makeMomentalrequest(ctx, opts.url())
 	if err != nil {
 		return nil, fmt.Errorf("error making API request: %w", err)
 	}
@@ -513,16 +606,15 @@ func handleMomentalHandle() (*http.Response, error) {
 	return resp, nil
 }
 
-func makeMomentalrequest(endpoint string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", endpoint, nil)
+func makeMomentalrequest(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	setHeaders(req)
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := fetchUpstream(req, req.URL.Path)
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
@@ -532,16 +624,26 @@ func makeMomentalrequest(endpoint string) (*http.Response, error) {
 
 // ВСПОМОГАТЕЛЬНЫЕ ФУНКЦИИ
 
-func makeAPIRequest(endpoint string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", baseURL+endpoint, nil)
+// upstreamClient общий для всех запросов к Stoloto; таймаут действует на одну попытку
+var upstreamClient = &http.Client{
+	Timeout:   30 * time.Second,
+	Transport: upstreamCassette,
+}
+
+func init() {
+	// Кассета снаружи бюджета: при воспроизведении запросы не тратят лимит
+	upstreamCassette.next = &budgetTransport{next: &decompressTransport{next: http.DefaultTransport}}
+}
+
+func makeAPIRequest(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", cfg.Upstream.BaseURL+endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	setHeaders(req)
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := fetchUpstream(req, endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
@@ -555,15 +657,42 @@ func setHeaders(req *http.Request) {
 	req.Header.Set("Accept", "application/json")
 }
 
-func forwardResponse(w http.ResponseWriter, resp *http.Response) {
-	for key, values := range resp.Header {
-		for _, value := range values {
-			w.Header().Add(key, value)
-		}
+// forwardResponse отдаёт ответ апстрима клиенту. Для успешных ответов
+// выставляются ETag, Cache-Control, Expires и Last-Modified по hints,
+// а If-None-Match/If-Modified-Since обрабатываются с ответом 304.
+func forwardResponse(w http.ResponseWriter, r *http.Request, resp *http.Response, hints cacheHintsFunc) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		sendError(w, "Error reading upstream response: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	copyUpstreamHeaders(w.Header(), resp.Header, cfg.Headers)
+	if age := ageSeconds(resp.Header); age > 0 {
+		w.Header().Set("Age", strconv.Itoa(age))
 	}
 
-	w.WriteHeader(resp.StatusCode)
-	io.Copy(w, resp.Body)
+	// Сбой апстрима — это не наша 5xx: клиенту отдаётся 502/504 с нашим телом ошибки
+	if resp.StatusCode >= 500 {
+		log.Printf("Upstream %s returned %s", resp.Request.URL.Path, resp.Status)
+		sendError(w, "Upstream error: "+resp.Status, upstreamStatus(resp.StatusCode))
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK || hints == nil {
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+		return
+	}
+
+	serveBody(w, r, body, hints(body, time.Now()), resp.Header)
+}
+
+func sendJSON(w http.ResponseWriter, v interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
 }
 
 func sendError(w http.ResponseWriter, message string, statusCode int) {
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metric — счётчик или gauge с набором меток. Значения отдаются на /metrics
+// в текстовом формате Prometheus.
+type metric struct {
+	name string
+	help string
+	kind string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+var metricsRegistry []*metric
+
+func newCounter(name, help string) *metric {
+	return registerMetric(name, help, "counter")
+}
+
+func newGauge(name, help string) *metric {
+	return registerMetric(name, help, "gauge")
+}
+
+func registerMetric(name, help, kind string) *metric {
+	m := &metric{name: name, help: help, kind: kind, values: make(map[string]float64)}
+	metricsRegistry = append(metricsRegistry, m)
+	return m
+}
+
+// add увеличивает значение для набора меток, полученного из labels
+func (m *metric) add(labels string, v float64) {
+	m.mu.Lock()
+	m.values[labels] += v
+	m.mu.Unlock()
+}
+
+func (m *metric) set(labels string, v float64) {
+	m.mu.Lock()
+	m.values[labels] = v
+	m.mu.Unlock()
+}
+
+// labels собирает метки из пар ключ-значение: labels("priority", "interactive")
+func labels(kv ...string) string {
+	parts := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		parts = append(parts, kv[i]+"="+strconv.Quote(kv[i+1]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// HandleMetrics godoc
+// @Summary Метрики прокси
+// @Description Метрики в текстовом формате Prometheus
+// @Tags service
+// @Produce plain
+// @Success 200 {string} string "Метрики"
+// @Router /metrics [get]
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	for _, m := range metricsRegistry {
+		m.mu.Lock()
+		keys := make([]string, 0, len(m.values))
+		for k := range m.values {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, k := range keys {
+			if k == "" {
+				fmt.Fprintf(w, "%s %g\n", m.name, m.values[k])
+			} else {
+				fmt.Fprintf(w, "%s{%s} %g\n", m.name, k, m.values[k])
+			}
+		}
+		m.mu.Unlock()
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Модели ответов Stoloto. Поля, которые прокси не использует, не описываются:
+// публичная схема строится из этих структур, а не из сырого JSON.
+
+type upstreamError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type upstreamGamesResponse struct {
+	RequestStatus string          `json:"requestStatus"`
+	Errors        []upstreamError `json:"errors"`
+	Games         []upstreamGame  `json:"games"`
+}
+
+type upstreamGame struct {
+	Name          string        `json:"name"`
+	Title         string        `json:"title"`
+	Type          string        `json:"type"`
+	SalesOpen     bool          `json:"salesOpen"`
+	Draw          *upstreamDraw `json:"draw"`
+	CompletedDraw *upstreamDraw `json:"completedDraw"`
+}
+
+type upstreamDrawResponse struct {
+	RequestStatus string          `json:"requestStatus"`
+	Errors        []upstreamError `json:"errors"`
+	Draw          *upstreamDraw   `json:"draw"`
+}
+
+type upstreamDraw struct {
+	ID          int64        `json:"id"`
+	Number      int          `json:"number"`
+	Date        upstreamDate `json:"date"`
+	Status      string       `json:"status"`
+	SuperPrize  float64      `json:"superPrize"`
+	Combination *struct {
+		Serialized []string `json:"serialized"`
+	} `json:"combination"`
+	WinningCategories []upstreamCategory `json:"winningCategories"`
+	TotalWinners      int                `json:"totalWinners"`
+	TotalPrize        float64            `json:"totalPrize"`
+}
+
+// completed — по тиражу уже известны результаты
+func (d *upstreamDraw) completed() bool {
+	return d.Status == "COMPLETED" || (d.Combination != nil && len(d.Combination.Serialized) > 0)
+}
+
+type upstreamCategory struct {
+	Number       int     `json:"number"`
+	Combination  string  `json:"combination"`
+	Participants int     `json:"participants"`
+	Amount       float64 `json:"amount"`
+}
+
+type upstreamMomentalResponse struct {
+	Status string          `json:"status"`
+	Errors []upstreamError `json:"errors"`
+	Data   struct {
+		Cards []upstreamCard `json:"cards"`
+	} `json:"data"`
+}
+
+type upstreamCard struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	MaxPrize  float64 `json:"maxPrize"`
+	Available bool    `json:"available"`
+	Badge     string  `json:"badge"`
+	Images    struct {
+		Small string `json:"small"`
+		Large string `json:"large"`
+	} `json:"images"`
+}
+
+// upstreamDate принимает все форматы дат, которые встречаются у Stoloto
+type upstreamDate struct {
+	time.Time
+}
+
+func (d *upstreamDate) UnmarshalJSON(b []byte) error {
+	t, ok := upstreamTime(b)
+	if !ok && string(b) != "null" {
+		return fmt.Errorf("unsupported date format: %s", b)
+	}
+	d.Time = t
+	return nil
+}
+
+var (
+	errDrawNotFound = errors.New("draw not found")
+	errGameNotFound = errors.New("game not found")
+	errCardNotFound = errors.New("moment card not found")
+)
+
+// upstreamStatusError — апстрим ответил кодом, из которого нельзя получить данные
+type upstreamStatusError struct {
+	Status     string
+	StatusCode int
+}
+
+func (e *upstreamStatusError) Error() string {
+	return "upstream returned " + e.Status
+}
+
+// upstreamAPIError — Stoloto ответил 200, но requestStatus не "success"
+type upstreamAPIError struct {
+	Errors []upstreamError
+}
+
+func (e *upstreamAPIError) Error() string {
+	if len(e.Errors) == 0 {
+		return "upstream reported an error"
+	}
+	parts := make([]string, len(e.Errors))
+	for i, ue := range e.Errors {
+		parts[i] = ue.Code
+		if ue.Message != "" {
+			parts[i] += ": " + ue.Message
+		}
+	}
+	return "upstream reported an error: " + strings.Join(parts, "; ")
+}
+
+// notFound — ошибка означает отсутствие запрошенного объекта (DRAW_NOT_FOUND
+// и т.п.), а не сбой апстрима
+func (e *upstreamAPIError) notFound() bool {
+	for _, ue := range e.Errors {
+		if strings.HasSuffix(ue.Code, "NOT_FOUND") {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeUpstream читает ответ апстрима в v. Вместе с данными возвращаются
+// заголовки ответа: по ним видно, что данные отданы из кеша или устарели.
+func decodeUpstream(resp *http.Response, v any) (http.Header, error) {
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &upstreamStatusError{Status: resp.Status, StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading upstream response: %w", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return nil, fmt.Errorf("error parsing upstream response: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// fetchGames запрашивает и разбирает список игр
+func fetchGames(ctx context.Context) (*upstreamGamesResponse, http.Header, error) {
+	resp, err := handleDrawsHandle(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var games upstreamGamesResponse
+	header, err := decodeUpstream(resp, &games)
+	if err != nil {
+		return nil, nil, err
+	}
+	if games.RequestStatus != "success" {
+		return nil, nil, fmt.Errorf("upstream requestStatus %q: %v", games.RequestStatus, games.Errors)
+	}
+	return &games, header, nil
+}
+
+// fetchDraw запрашивает и разбирает тираж; несуществующий тираж — errDrawNotFound,
+// другие ошибки в ответе Stoloto — upstreamAPIError
+func fetchDraw(ctx context.Context, name string, number int) (*upstreamDraw, http.Header, error) {
+	resp, err := handleDrawHandle(ctx, name, strconv.Itoa(number))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var draw upstreamDrawResponse
+	header, err := decodeUpstream(resp, &draw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if draw.RequestStatus != "success" {
+		apiErr := &upstreamAPIError{Errors: draw.Errors}
+		if !apiErr.notFound() {
+			return nil, nil, fmt.Errorf("%s #%d: %w", name, number, apiErr)
+		}
+		return nil, nil, fmt.Errorf("%w: %s #%d", errDrawNotFound, name, number)
+	}
+	if draw.Draw == nil {
+		return nil, nil, fmt.Errorf("%w: %s #%d", errDrawNotFound, name, number)
+	}
+	return draw.Draw, header, nil
+}
+
+// momentalOptions — параметры витрины моментальных лотерей в CMS. Пустые
+// поля берутся из upstream.momentalURL (по умолчанию OS и ALL).
+type momentalOptions struct {
+	Platform    string
+	UserSegment string
+}
+
+func (o momentalOptions) url() string {
+	if o == (momentalOptions{}) {
+		return cfg.Upstream.MomentalURL
+	}
+	u, err := url.Parse(cfg.Upstream.MomentalURL)
+	if err != nil {
+		return cfg.Upstream.MomentalURL
+	}
+	q := u.Query()
+	if o.Platform != "" {
+		q.Set("platform", o.Platform)
+	}
+	if o.UserSegment != "" {
+		q.Set("user-segment", o.UserSegment)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// fetchMomentalCards запрашивает и разбирает карточки моментальных лотерей
+func fetchMomentalCards(ctx context.Context, opts momentalOptions) ([]upstreamCard, http.Header, error) {
+	resp, err := handleMomentalHandle(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var section upstreamMomentalResponse
+	header, err := decodeUpstream(resp, &section)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Документ об ошибке CMS — не пустая витрина
+	if section.Status != "success" {
+		return nil, nil, &upstreamAPIError{Errors: section.Errors}
+	}
+	return section.Data.Cards, header, nil
+}
+
+// findGame ищет игру по имени; в ошибке перечислены существующие игры
+func findGame(ctx context.Context, name string) (*upstreamGame, *upstreamGamesResponse, http.Header, error) {
+	games, header, err := fetchGames(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	game := games.find(name)
+	if game == nil {
+		return nil, nil, nil, fmt.Errorf("%w: '%s'. Available games: %v", errGameNotFound, name, games.names())
+	}
+	return game, games, header, nil
+}
+
+// fetchLatestDraw возвращает последний проведённый тираж игры. Номер берётся
+// из списка игр, поэтому если список устарел, в заголовках будет отметка об этом.
+func fetchLatestDraw(ctx context.Context, name string) (*upstreamDraw, http.Header, error) {
+	game, _, gamesHeader, err := findGame(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if game.CompletedDraw == nil {
+		return nil, nil, fmt.Errorf("%w: no completed draws for '%s'", errDrawNotFound, name)
+	}
+
+	// В списке игр тираж может быть без выигрышных категорий — берём полный
+	draw, header, err := fetchDraw(ctx, name, game.CompletedDraw.Number)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Get("X-Data-Stale") == "" && gamesHeader.Get("X-Data-Stale") != "" {
+		header = gamesHeader
+	}
+	return draw, header, nil
+}
+
+func (g *upstreamGamesResponse) find(name string) *upstreamGame {
+	for i := range g.Games {
+		if g.Games[i].Name == name {
+			return &g.Games[i]
+		}
+	}
+	return nil
+}
+
+func (g *upstreamGamesResponse) names() []string {
+	names := make([]string, 0, len(g.Games))
+	for _, game := range g.Games {
+		names = append(names, game.Name)
+	}
+	return names
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Отслеживание витрины моментальных лотерей: периодический снимок карточек
+// сравнивается с предыдущим, найденные изменения пишутся в журнал и
+// рассылаются подписчикам (gRPC WatchMomentalChanges).
+
+// MomentalChangesConfig — снимки витрины моментальных лотерей
+type MomentalChangesConfig struct {
+	// PollInterval — как часто снимается витрина; 0 отключает отслеживание.
+	// Снимок берётся через кеш, так что чаще momentalMaxAge смысла нет.
+	PollInterval Duration `json:"pollInterval"`
+	// File — где хранить последний снимок и журнал. Без файла журнал живёт
+	// до перезапуска, а изменения за время простоя не замечаются.
+	File string `json:"file"`
+	// MaxChanges — сколько последних изменений хранить
+	MaxChanges int `json:"maxChanges"`
+}
+
+const (
+	momentalAdded   = "added"
+	momentalRemoved = "removed"
+	momentalChanged = "changed"
+)
+
+// MomentalChangeV1 — изменение витрины моментальных лотерей
+type MomentalChangeV1 struct {
+	// Time — когда изменение замечено (время снимка)
+	Time time.Time `json:"time"`
+	Type string    `json:"type" enums:"added,removed,changed"`
+	// Card — карточка после изменения; у удалённой — последнее известное состояние
+	Card MomentCardV1 `json:"card"`
+	// Previous — карточка до изменения, только у changed
+	Previous *MomentCardV1 `json:"previous,omitempty"`
+	// Fields — изменившиеся поля у changed: price, maxPrize
+	Fields []string `json:"fields,omitempty"`
+}
+
+// MomentalChangesV1 — журнал изменений витрины
+type MomentalChangesV1 struct {
+	Changes []MomentalChangeV1 `json:"changes"`
+	// SnapshotAt — время последнего снимка; изменения после него ещё не известны
+	SnapshotAt *time.Time `json:"snapshotAt,omitempty"`
+}
+
+var momentalChangesTotal = newCounter("stoloto_momental_changes_total", "Momental catalogue changes detected between snapshots")
+
+// momentalState — то, что сохраняется в MomentalChangesConfig.File
+type momentalState struct {
+	SnapshotAt time.Time          `json:"snapshotAt"`
+	Cards      []MomentCardV1     `json:"cards"`
+	Changes    []MomentalChangeV1 `json:"changes"`
+}
+
+type momentalTracker struct {
+	eventHub[MomentalChangeV1]
+	mu      sync.RWMutex
+	state   momentalState
+	running bool
+}
+
+var momentalChanges = &momentalTracker{}
+
+// start загружает сохранённое состояние и запускает снимки. Если фоновая
+// задача не запущена (сервер уже останавливается), журнал считается
+// отключённым, а не замершим.
+func (t *momentalTracker) start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if c := cfg.MomentalChanges; c.File != "" {
+		data, err := os.ReadFile(c.File)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return fmt.Errorf("error reading momental changes: %w", err)
+		default:
+			if err := json.Unmarshal(data, &t.state); err != nil {
+				return fmt.Errorf("error parsing momental changes %s: %w", c.File, err)
+			}
+		}
+	}
+	// since ищет по журналу двоичным поиском, а файл могли править руками
+	slices.SortStableFunc(t.state.Changes, func(a, b MomentalChangeV1) int { return a.Time.Compare(b.Time) })
+
+	t.running = background.Go("momental-changes", t.run)
+	return nil
+}
+
+func (t *momentalTracker) run(ctx context.Context) {
+	defer t.closeAll()
+	ctx = withPriority(ctx, priorityBackground)
+
+	ticker := time.NewTicker(time.Duration(cfg.MomentalChanges.PollInterval))
+	defer ticker.Stop()
+
+	for {
+		cards, _, err := fetchMomentalCards(ctx, momentalOptions{})
+		switch {
+		case err != nil:
+			log.Printf("Momental changes: %v", err)
+		case len(cards) == 0:
+			// Пустая витрина — скорее сбой CMS, чем снятие всех лотерей сразу
+			log.Printf("Momental changes: empty catalogue, snapshot skipped")
+		default:
+			snapshot := make([]MomentCardV1, len(cards))
+			for i := range cards {
+				snapshot[i] = toMomentCardV1(&cards[i])
+			}
+			t.record(time.Now(), snapshot)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// record сравнивает снимок с предыдущим, дописывает журнал и рассылает
+// изменения. Первый снимок только запоминается.
+func (t *momentalTracker) record(now time.Time, cards []MomentCardV1) {
+	t.mu.Lock()
+	var changes []MomentalChangeV1
+	if !t.state.SnapshotAt.IsZero() {
+		changes = diffMomentCards(now, t.state.Cards, cards)
+	}
+	t.state.SnapshotAt = now
+	t.state.Cards = cards
+	t.state.Changes = append(t.state.Changes, changes...)
+	if limit := cfg.MomentalChanges.MaxChanges; limit > 0 && len(t.state.Changes) > limit {
+		t.state.Changes = slices.Clone(t.state.Changes[len(t.state.Changes)-limit:])
+	}
+	err := t.save()
+	t.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Momental changes: %v", err)
+	}
+	for _, c := range changes {
+		log.Printf("Momental card %s %s (%s)", c.Card.ID, c.Type, c.Card.Name)
+		momentalChangesTotal.add(labels("type", c.Type), 1)
+		t.publish(c)
+	}
+}
+
+// save пишет состояние в файл; вызывается под t.mu
+func (t *momentalTracker) save() error {
+	file := cfg.MomentalChanges.File
+	if file == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error saving momental changes: %w", err)
+	}
+	return os.Rename(tmp, file)
+}
+
+// since возвращает изменения, замеченные позже since, и время последнего снимка
+func (t *momentalTracker) since(since time.Time) ([]MomentalChangeV1, time.Time) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	i, _ := slices.BinarySearchFunc(t.state.Changes, since, func(c MomentalChangeV1, s time.Time) int {
+		if c.Time.After(s) {
+			return 1
+		}
+		return -1
+	})
+	return slices.Clone(t.state.Changes[i:]), t.state.SnapshotAt
+}
+
+func (t *momentalTracker) enabled() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.running
+}
+
+// diffMomentCards находит добавленные, удалённые и изменившие цену или
+// максимальный выигрыш карточки
+func diffMomentCards(now time.Time, prev, cur []MomentCardV1) []MomentalChangeV1 {
+	before := make(map[string]MomentCardV1, len(prev))
+	for _, c := range prev {
+		before[c.ID] = c
+	}
+	after := make(map[string]bool, len(cur))
+
+	var changes []MomentalChangeV1
+	for _, c := range cur {
+		after[c.ID] = true
+		old, ok := before[c.ID]
+		if !ok {
+			changes = append(changes, MomentalChangeV1{Time: now, Type: momentalAdded, Card: c})
+			continue
+		}
+
+		var fields []string
+		if c.Price != old.Price {
+			fields = append(fields, "price")
+		}
+		if c.MaxPrize != old.MaxPrize {
+			fields = append(fields, "maxPrize")
+		}
+		if len(fields) > 0 {
+			changes = append(changes, MomentalChangeV1{Time: now, Type: momentalChanged, Card: c, Previous: &old, Fields: fields})
+		}
+	}
+	for _, c := range prev {
+		if !after[c.ID] {
+			changes = append(changes, MomentalChangeV1{Time: now, Type: momentalRemoved, Card: c})
+		}
+	}
+	return changes
+}
+
+// V1MomentalChanges godoc
+// @Summary Изменения витрины моментальных лотерей
+// @Description Добавленные, удалённые и изменившие цену или максимальный выигрыш карточки по сравнению снимков витрины. Без since — весь хранимый журнал.
+// @Tags v1
+// @Produce json
+// @Security ApiKeyAuth
+// @Param since query string false "Только изменения позже этого момента (RFC 3339)"
+// @Success 200 {object} MomentalChangesV1
+// @Failure 400 {object} ErrorResponse "Неверные параметры"
+// @Failure 503 {object} ErrorResponse "Отслеживание отключено"
+// @Router /api/v1/momental/changes [get]
+// @Router /api/momental/changes [get]
+func handleV1MomentalChanges(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			sendError(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+	if !momentalChanges.enabled() {
+		sendError(w, "Momental change tracking is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	changes, snapshotAt := momentalChanges.since(since)
+	out := MomentalChangesV1{Changes: changes}
+	if out.Changes == nil {
+		out.Changes = []MomentalChangeV1{}
+	}
+	if !snapshotAt.IsZero() {
+		out.SnapshotAt = &snapshotAt
+	}
+	serveAPI(w, r, out, cacheHints{lastModified: snapshotAt}, nil)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffMomentCards(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	card := func(id string, price, prize int64) MomentCardV1 {
+		return MomentCardV1{ID: id, Name: "Card " + id, Price: price, MaxPrize: prize}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		prev, cur []MomentCardV1
+		want      []string // тип:id:поля
+	}{
+		{"unchanged", []MomentCardV1{card("a", 100, 1000)}, []MomentCardV1{card("a", 100, 1000)}, nil},
+		{"added", []MomentCardV1{card("a", 100, 1000)}, []MomentCardV1{card("a", 100, 1000), card("b", 50, 500)}, []string{"added:b:"}},
+		{"removed", []MomentCardV1{card("a", 100, 1000), card("b", 50, 500)}, []MomentCardV1{card("b", 50, 500)}, []string{"removed:a:"}},
+		{"price", []MomentCardV1{card("a", 100, 1000)}, []MomentCardV1{card("a", 150, 1000)}, []string{"changed:a:price"}},
+		{"price and prize", []MomentCardV1{card("a", 100, 1000)}, []MomentCardV1{card("a", 150, 2000)}, []string{"changed:a:price,maxPrize"}},
+		// Другие поля, например доступность, изменением не считаются
+		{"availability", []MomentCardV1{card("a", 100, 1000)}, []MomentCardV1{{ID: "a", Name: "Card a", Price: 100, MaxPrize: 1000, Available: true}}, nil},
+		{"all at once", []MomentCardV1{card("a", 100, 1000), card("b", 50, 500)}, []MomentCardV1{card("b", 50, 700), card("c", 20, 200)},
+			[]string{"changed:b:maxPrize", "added:c:", "removed:a:"}},
+	} {
+		changes := diffMomentCards(now, tc.prev, tc.cur)
+		var got []string
+		for _, c := range changes {
+			if !c.Time.Equal(now) {
+				t.Errorf("%s: change time %v, want %v", tc.name, c.Time, now)
+			}
+			if (c.Type == momentalChanged) != (c.Previous != nil) {
+				t.Errorf("%s: %s change with previous %v", tc.name, c.Type, c.Previous)
+			}
+			got = append(got, c.Type+":"+c.Card.ID+":"+strings.Join(c.Fields, ","))
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: changes = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMomentalChangesSince(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+
+	tracker := &momentalTracker{state: momentalState{SnapshotAt: at(30)}}
+	for _, m := range []int{0, 10, 10, 20} {
+		tracker.state.Changes = append(tracker.state.Changes, MomentalChangeV1{Time: at(m), Type: momentalAdded})
+	}
+
+	for _, tc := range []struct {
+		since time.Time
+		want  int
+	}{
+		{time.Time{}, 4},
+		{at(-1), 4},
+		// Граница не включается: клиент передаёт время уже полученного изменения
+		{at(0), 3},
+		{at(10), 1},
+		{at(15), 1},
+		{at(20), 0},
+		{at(40), 0},
+	} {
+		changes, snapshotAt := tracker.since(tc.since)
+		if len(changes) != tc.want {
+			t.Errorf("since %v: %d changes, want %d", tc.since, len(changes), tc.want)
+		}
+		if !snapshotAt.Equal(at(30)) {
+			t.Errorf("since %v: snapshotAt %v, want %v", tc.since, snapshotAt, at(30))
+		}
+	}
+}
+
+func TestMomentalChangesPersistence(t *testing.T) {
+	prevCfg, prevBackground := cfg, background
+	t.Cleanup(func() { cfg, background = prevCfg, prevBackground })
+
+	cfg = defaultConfig()
+	cfg.MomentalChanges.File = filepath.Join(t.TempDir(), "momental.json")
+	cfg.MomentalChanges.MaxChanges = 2
+
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	saved := &momentalTracker{}
+	saved.record(base, []MomentCardV1{{ID: "a", Price: 100}})
+	saved.record(base.Add(time.Minute), []MomentCardV1{{ID: "a", Price: 150}, {ID: "b"}})
+	saved.record(base.Add(2*time.Minute), []MomentCardV1{{ID: "b"}})
+	wantChanges, wantSnapshot := saved.since(time.Time{})
+	if len(wantChanges) != 2 {
+		t.Fatalf("journal has %d changes, want maxChanges 2", len(wantChanges))
+	}
+
+	// Фоновые задачи остановлены: состояние загружается, но отслеживание не
+	// считается включённым
+	background = newWorkerGroup()
+	if err := background.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &momentalTracker{}
+	if err := loaded.start(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.enabled() {
+		t.Fatal("tracker reported enabled although its worker was refused")
+	}
+
+	changes, snapshotAt := loaded.since(time.Time{})
+	if !snapshotAt.Equal(wantSnapshot) || len(changes) != len(wantChanges) {
+		t.Fatalf("loaded %d changes at %v, want %d at %v", len(changes), snapshotAt, len(wantChanges), wantSnapshot)
+	}
+	for i := range changes {
+		if changes[i].Type != wantChanges[i].Type || changes[i].Card.ID != wantChanges[i].Card.ID || !changes[i].Time.Equal(wantChanges[i].Time) {
+			t.Fatalf("change %d = %+v, want %+v", i, changes[i], wantChanges[i])
+		}
+	}
+
+	// Журнал, записанный не по порядку, упорядочивается при загрузке
+	data, err := json.Marshal(momentalState{SnapshotAt: base, Changes: []MomentalChangeV1{
+		{Time: base, Type: momentalAdded},
+		{Time: base.Add(-time.Hour), Type: momentalRemoved},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfg.MomentalChanges.File, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	unsorted := &momentalTracker{}
+	if err := unsorted.start(); err != nil {
+		t.Fatal(err)
+	}
+	if changes, _ := unsorted.since(base.Add(-time.Minute)); len(changes) != 1 || changes[0].Type != momentalAdded {
+		t.Fatalf("since on a reordered journal = %+v, want the added change only", changes)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Поиск, фильтрация и сортировка карточек моментальных лотерей. Как и список
+// игр, витрина небольшая и приходит целиком, всё делается в памяти.
+
+var errInvalidCardsQuery = errors.New("invalid query")
+
+// parseMomentalOptions читает platform и userSegment (или user-segment, как у CMS).
+// Значения подставляются в запрос к CMS и в ключ кеша, поэтому принимаются
+// только перечисленные в upstream.momentalPlatforms и momentalUserSegments:
+// иначе любой клиент обходил бы кеш, перебирая строки.
+func parseMomentalOptions(q url.Values) (momentalOptions, error) {
+	opts := momentalOptions{Platform: q.Get("platform"), UserSegment: q.Get("userSegment")}
+	if opts.UserSegment == "" {
+		opts.UserSegment = q.Get("user-segment")
+	}
+
+	for _, o := range []struct {
+		name    string
+		v       *string
+		allowed []string
+	}{
+		{"platform", &opts.Platform, cfg.Upstream.MomentalPlatforms},
+		{"userSegment", &opts.UserSegment, cfg.Upstream.MomentalUserSegments},
+	} {
+		if *o.v == "" {
+			continue
+		}
+		i := slices.IndexFunc(o.allowed, func(a string) bool { return strings.EqualFold(a, *o.v) })
+		if i < 0 {
+			return opts, fmt.Errorf("%w: unknown %s %q, expected one of %v", errInvalidCardsQuery, o.name, *o.v, o.allowed)
+		}
+		*o.v = strings.ToUpper(o.allowed[i])
+	}
+	return opts, nil
+}
+
+// cardsQuery — разобранные параметры поиска карточек
+type cardsQuery struct {
+	search    string
+	available *bool
+	// Границы включаются; nil — без ограничения
+	priceFrom, priceTo *int64
+	prizeFrom, prizeTo *int64
+	sortBy             string
+	desc               bool
+}
+
+var cardSorts = map[string]func(a, b MomentCardV1) int{
+	"name":     func(a, b MomentCardV1) int { return strings.Compare(a.Name, b.Name) },
+	"price":    func(a, b MomentCardV1) int { return cmp.Compare(a.Price, b.Price) },
+	"maxPrize": func(a, b MomentCardV1) int { return cmp.Compare(a.MaxPrize, b.MaxPrize) },
+}
+
+func parseCardsQuery(q url.Values) (cardsQuery, error) {
+	cq := cardsQuery{search: strings.ToLower(strings.TrimSpace(q.Get("q")))}
+
+	if v := q.Get("available"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return cq, fmt.Errorf("%w: available must be true or false", errInvalidCardsQuery)
+		}
+		cq.available = &b
+	}
+
+	for name, dst := range map[string]**int64{
+		"priceFrom": &cq.priceFrom, "priceTo": &cq.priceTo,
+		"prizeFrom": &cq.prizeFrom, "prizeTo": &cq.prizeTo,
+	} {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return cq, fmt.Errorf("%w: %s must be a non-negative number of rubles", errInvalidCardsQuery, name)
+			}
+			*dst = &n
+		}
+	}
+
+	if v := q.Get("sort"); v != "" {
+		cq.sortBy, cq.desc = strings.CutPrefix(v, "-")
+		if _, ok := cardSorts[cq.sortBy]; !ok {
+			return cq, fmt.Errorf("%w: unknown sort %q, expected name, price or maxPrize", errInvalidCardsQuery, v)
+		}
+	}
+	return cq, nil
+}
+
+func (cq cardsQuery) match(c MomentCardV1) bool {
+	inRange := func(v int64, from, to *int64) bool {
+		return (from == nil || v >= *from) && (to == nil || v <= *to)
+	}
+
+	switch {
+	case cq.search != "" && !strings.Contains(strings.ToLower(c.Name), cq.search):
+		return false
+	case cq.available != nil && c.Available != *cq.available:
+		return false
+	case !inRange(c.Price, cq.priceFrom, cq.priceTo):
+		return false
+	case !inRange(c.MaxPrize, cq.prizeFrom, cq.prizeTo):
+		return false
+	}
+	return true
+}
+
+func (cq cardsQuery) apply(cards []MomentCardV1) []MomentCardV1 {
+	out := []MomentCardV1{}
+	for _, c := range cards {
+		if cq.match(c) {
+			out = append(out, c)
+		}
+	}
+
+	if compare, ok := cardSorts[cq.sortBy]; ok {
+		slices.SortStableFunc(out, func(a, b MomentCardV1) int {
+			if cq.desc {
+				return compare(b, a)
+			}
+			return compare(a, b)
+		})
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"DOUPIG/fakestoloto"
+)
+
+func TestParseMomentalOptions(t *testing.T) {
+	for _, tc := range []struct {
+		query url.Values
+		want  momentalOptions
+		ok    bool
+	}{
+		{url.Values{}, momentalOptions{}, true},
+		{url.Values{"platform": {"ios"}, "userSegment": {"All"}}, momentalOptions{Platform: "IOS", UserSegment: "ALL"}, true},
+		{url.Values{"user-segment": {"ALL"}}, momentalOptions{UserSegment: "ALL"}, true},
+		// Неизвестные значения — новый ключ кеша и запрос к CMS на каждый
+		{url.Values{"platform": {"OS1"}}, momentalOptions{}, false},
+		{url.Values{"userSegment": {"VIP"}}, momentalOptions{}, false},
+	} {
+		got, err := parseMomentalOptions(tc.query)
+		if tc.ok && (err != nil || got != tc.want) {
+			t.Errorf("%v: got %+v, %v; want %+v", tc.query, got, err, tc.want)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%v: accepted as %+v", tc.query, got)
+		}
+	}
+}
+
+func TestMomentalCardsSurfaceCMSErrors(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+
+	var cards MomentCardsV1
+	if resp := getJSON(t, proxy.URL+"/api/v1/momental/cards", &cards); resp.StatusCode != http.StatusOK || len(cards.Cards) == 0 {
+		t.Fatalf("cards: status %d, %d cards", resp.StatusCode, len(cards.Cards))
+	}
+
+	// Документ об ошибке с HTTP 200 — сбой CMS, а не пустая витрина
+	fake.InjectFault(fakestoloto.Fault{Path: fakestoloto.MomentalPath, Status: http.StatusOK})
+	if resp := getJSON(t, proxy.URL+"/api/v1/momental/cards", nil); resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("cards with CMS error: status %d, want 502", resp.StatusCode)
+	}
+}
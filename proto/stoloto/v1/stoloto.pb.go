@@ -0,0 +1,1138 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: stoloto/v1/stoloto.proto
+
+package stolotov1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DrawStatus int32
+
+const (
+	DrawStatus_DRAW_STATUS_UNSPECIFIED DrawStatus = 0
+	DrawStatus_DRAW_STATUS_OPEN        DrawStatus = 1
+	DrawStatus_DRAW_STATUS_COMPLETED   DrawStatus = 2
+)
+
+// Enum value maps for DrawStatus.
+var (
+	DrawStatus_name = map[int32]string{
+		0: "DRAW_STATUS_UNSPECIFIED",
+		1: "DRAW_STATUS_OPEN",
+		2: "DRAW_STATUS_COMPLETED",
+	}
+	DrawStatus_value = map[string]int32{
+		"DRAW_STATUS_UNSPECIFIED": 0,
+		"DRAW_STATUS_OPEN":        1,
+		"DRAW_STATUS_COMPLETED":   2,
+	}
+)
+
+func (x DrawStatus) Enum() *DrawStatus {
+	p := new(DrawStatus)
+	*p = x
+	return p
+}
+
+func (x DrawStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DrawStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_stoloto_v1_stoloto_proto_enumTypes[0].Descriptor()
+}
+
+func (DrawStatus) Type() protoreflect.EnumType {
+	return &file_stoloto_v1_stoloto_proto_enumTypes[0]
+}
+
+func (x DrawStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DrawStatus.Descriptor instead.
+func (DrawStatus) EnumDescriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{0}
+}
+
+type DrawEvent_Type int32
+
+const (
+	DrawEvent_TYPE_UNSPECIFIED DrawEvent_Type = 0
+	DrawEvent_TYPE_OPENED      DrawEvent_Type = 1
+	DrawEvent_TYPE_COMPLETED   DrawEvent_Type = 2
+)
+
+// Enum value maps for DrawEvent_Type.
+var (
+	DrawEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_OPENED",
+		2: "TYPE_COMPLETED",
+	}
+	DrawEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_OPENED":      1,
+		"TYPE_COMPLETED":   2,
+	}
+)
+
+func (x DrawEvent_Type) Enum() *DrawEvent_Type {
+	p := new(DrawEvent_Type)
+	*p = x
+	return p
+}
+
+func (x DrawEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DrawEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_stoloto_v1_stoloto_proto_enumTypes[1].Descriptor()
+}
+
+func (DrawEvent_Type) Type() protoreflect.EnumType {
+	return &file_stoloto_v1_stoloto_proto_enumTypes[1]
+}
+
+func (x DrawEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DrawEvent_Type.Descriptor instead.
+func (DrawEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{9, 0}
+}
+
+type MomentalChange_Type int32
+
+const (
+	MomentalChange_TYPE_UNSPECIFIED MomentalChange_Type = 0
+	MomentalChange_TYPE_ADDED       MomentalChange_Type = 1
+	MomentalChange_TYPE_REMOVED     MomentalChange_Type = 2
+	MomentalChange_TYPE_CHANGED     MomentalChange_Type = 3
+)
+
+// Enum value maps for MomentalChange_Type.
+var (
+	MomentalChange_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_ADDED",
+		2: "TYPE_REMOVED",
+		3: "TYPE_CHANGED",
+	}
+	MomentalChange_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_ADDED":       1,
+		"TYPE_REMOVED":     2,
+		"TYPE_CHANGED":     3,
+	}
+)
+
+func (x MomentalChange_Type) Enum() *MomentalChange_Type {
+	p := new(MomentalChange_Type)
+	*p = x
+	return p
+}
+
+func (x MomentalChange_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MomentalChange_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_stoloto_v1_stoloto_proto_enumTypes[2].Descriptor()
+}
+
+func (MomentalChange_Type) Type() protoreflect.EnumType {
+	return &file_stoloto_v1_stoloto_proto_enumTypes[2]
+}
+
+func (x MomentalChange_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MomentalChange_Type.Descriptor instead.
+func (MomentalChange_Type) EnumDescriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{12, 0}
+}
+
+type Game struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	SalesOpen     bool                   `protobuf:"varint,4,opt,name=sales_open,json=salesOpen,proto3" json:"sales_open,omitempty"`
+	CurrentDraw   *Draw                  `protobuf:"bytes,5,opt,name=current_draw,json=currentDraw,proto3" json:"current_draw,omitempty"`
+	LastDraw      *Draw                  `protobuf:"bytes,6,opt,name=last_draw,json=lastDraw,proto3" json:"last_draw,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Game) Reset() {
+	*x = Game{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Game) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Game) ProtoMessage() {}
+
+func (x *Game) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Game.ProtoReflect.Descriptor instead.
+func (*Game) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Game) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Game) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Game) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Game) GetSalesOpen() bool {
+	if x != nil {
+		return x.SalesOpen
+	}
+	return false
+}
+
+func (x *Game) GetCurrentDraw() *Draw {
+	if x != nil {
+		return x.CurrentDraw
+	}
+	return nil
+}
+
+func (x *Game) GetLastDraw() *Draw {
+	if x != nil {
+		return x.LastDraw
+	}
+	return nil
+}
+
+type Draw struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Game          string                 `protobuf:"bytes,1,opt,name=game,proto3" json:"game,omitempty"`
+	Number        int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=date,proto3" json:"date,omitempty"`
+	Status        DrawStatus             `protobuf:"varint,4,opt,name=status,proto3,enum=stoloto.v1.DrawStatus" json:"status,omitempty"`
+	SuperPrize    int64                  `protobuf:"varint,5,opt,name=super_prize,json=superPrize,proto3" json:"super_prize,omitempty"`
+	Numbers       []int32                `protobuf:"varint,6,rep,packed,name=numbers,proto3" json:"numbers,omitempty"`
+	Prizes        []*PrizeCategory       `protobuf:"bytes,7,rep,name=prizes,proto3" json:"prizes,omitempty"`
+	TotalWinners  int32                  `protobuf:"varint,8,opt,name=total_winners,json=totalWinners,proto3" json:"total_winners,omitempty"`
+	TotalPrize    int64                  `protobuf:"varint,9,opt,name=total_prize,json=totalPrize,proto3" json:"total_prize,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Draw) Reset() {
+	*x = Draw{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Draw) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Draw) ProtoMessage() {}
+
+func (x *Draw) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Draw.ProtoReflect.Descriptor instead.
+func (*Draw) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Draw) GetGame() string {
+	if x != nil {
+		return x.Game
+	}
+	return ""
+}
+
+func (x *Draw) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Draw) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *Draw) GetStatus() DrawStatus {
+	if x != nil {
+		return x.Status
+	}
+	return DrawStatus_DRAW_STATUS_UNSPECIFIED
+}
+
+func (x *Draw) GetSuperPrize() int64 {
+	if x != nil {
+		return x.SuperPrize
+	}
+	return 0
+}
+
+func (x *Draw) GetNumbers() []int32 {
+	if x != nil {
+		return x.Numbers
+	}
+	return nil
+}
+
+func (x *Draw) GetPrizes() []*PrizeCategory {
+	if x != nil {
+		return x.Prizes
+	}
+	return nil
+}
+
+func (x *Draw) GetTotalWinners() int32 {
+	if x != nil {
+		return x.TotalWinners
+	}
+	return 0
+}
+
+func (x *Draw) GetTotalPrize() int64 {
+	if x != nil {
+		return x.TotalPrize
+	}
+	return 0
+}
+
+type PrizeCategory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      int32                  `protobuf:"varint,1,opt,name=category,proto3" json:"category,omitempty"`
+	Matched       string                 `protobuf:"bytes,2,opt,name=matched,proto3" json:"matched,omitempty"`
+	Winners       int32                  `protobuf:"varint,3,opt,name=winners,proto3" json:"winners,omitempty"`
+	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrizeCategory) Reset() {
+	*x = PrizeCategory{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrizeCategory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrizeCategory) ProtoMessage() {}
+
+func (x *PrizeCategory) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrizeCategory.ProtoReflect.Descriptor instead.
+func (*PrizeCategory) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PrizeCategory) GetCategory() int32 {
+	if x != nil {
+		return x.Category
+	}
+	return 0
+}
+
+func (x *PrizeCategory) GetMatched() string {
+	if x != nil {
+		return x.Matched
+	}
+	return ""
+}
+
+func (x *PrizeCategory) GetWinners() int32 {
+	if x != nil {
+		return x.Winners
+	}
+	return 0
+}
+
+func (x *PrizeCategory) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type GetGamesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGamesRequest) Reset() {
+	*x = GetGamesRequest{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGamesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGamesRequest) ProtoMessage() {}
+
+func (x *GetGamesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGamesRequest.ProtoReflect.Descriptor instead.
+func (*GetGamesRequest) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{3}
+}
+
+type GetGamesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Games         []*Game                `protobuf:"bytes,1,rep,name=games,proto3" json:"games,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGamesResponse) Reset() {
+	*x = GetGamesResponse{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGamesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGamesResponse) ProtoMessage() {}
+
+func (x *GetGamesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGamesResponse.ProtoReflect.Descriptor instead.
+func (*GetGamesResponse) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetGamesResponse) GetGames() []*Game {
+	if x != nil {
+		return x.Games
+	}
+	return nil
+}
+
+type GetDrawRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Game          string                 `protobuf:"bytes,1,opt,name=game,proto3" json:"game,omitempty"`
+	Number        int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDrawRequest) Reset() {
+	*x = GetDrawRequest{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDrawRequest) ProtoMessage() {}
+
+func (x *GetDrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDrawRequest.ProtoReflect.Descriptor instead.
+func (*GetDrawRequest) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetDrawRequest) GetGame() string {
+	if x != nil {
+		return x.Game
+	}
+	return ""
+}
+
+func (x *GetDrawRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+type GetLatestDrawRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Game          string                 `protobuf:"bytes,1,opt,name=game,proto3" json:"game,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestDrawRequest) Reset() {
+	*x = GetLatestDrawRequest{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestDrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestDrawRequest) ProtoMessage() {}
+
+func (x *GetLatestDrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestDrawRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestDrawRequest) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetLatestDrawRequest) GetGame() string {
+	if x != nil {
+		return x.Game
+	}
+	return ""
+}
+
+type ListDrawsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Game          string                 `protobuf:"bytes,1,opt,name=game,proto3" json:"game,omitempty"`
+	From          int32                  `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            int32                  `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDrawsRequest) Reset() {
+	*x = ListDrawsRequest{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDrawsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDrawsRequest) ProtoMessage() {}
+
+func (x *ListDrawsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDrawsRequest.ProtoReflect.Descriptor instead.
+func (*ListDrawsRequest) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListDrawsRequest) GetGame() string {
+	if x != nil {
+		return x.Game
+	}
+	return ""
+}
+
+func (x *ListDrawsRequest) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ListDrawsRequest) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+type WatchDrawsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Games         []string               `protobuf:"bytes,1,rep,name=games,proto3" json:"games,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchDrawsRequest) Reset() {
+	*x = WatchDrawsRequest{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchDrawsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchDrawsRequest) ProtoMessage() {}
+
+func (x *WatchDrawsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchDrawsRequest.ProtoReflect.Descriptor instead.
+func (*WatchDrawsRequest) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchDrawsRequest) GetGames() []string {
+	if x != nil {
+		return x.Games
+	}
+	return nil
+}
+
+type DrawEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          DrawEvent_Type         `protobuf:"varint,1,opt,name=type,proto3,enum=stoloto.v1.DrawEvent_Type" json:"type,omitempty"`
+	Draw          *Draw                  `protobuf:"bytes,2,opt,name=draw,proto3" json:"draw,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrawEvent) Reset() {
+	*x = DrawEvent{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrawEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrawEvent) ProtoMessage() {}
+
+func (x *DrawEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrawEvent.ProtoReflect.Descriptor instead.
+func (*DrawEvent) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DrawEvent) GetType() DrawEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return DrawEvent_TYPE_UNSPECIFIED
+}
+
+func (x *DrawEvent) GetDraw() *Draw {
+	if x != nil {
+		return x.Draw
+	}
+	return nil
+}
+
+type MomentCard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Price         int64                  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	MaxPrize      int64                  `protobuf:"varint,4,opt,name=max_prize,json=maxPrize,proto3" json:"max_prize,omitempty"`
+	Available     bool                   `protobuf:"varint,5,opt,name=available,proto3" json:"available,omitempty"`
+	ImageUrl      string                 `protobuf:"bytes,6,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MomentCard) Reset() {
+	*x = MomentCard{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MomentCard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MomentCard) ProtoMessage() {}
+
+func (x *MomentCard) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MomentCard.ProtoReflect.Descriptor instead.
+func (*MomentCard) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MomentCard) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MomentCard) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MomentCard) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *MomentCard) GetMaxPrize() int64 {
+	if x != nil {
+		return x.MaxPrize
+	}
+	return 0
+}
+
+func (x *MomentCard) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *MomentCard) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+type WatchMomentalChangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMomentalChangesRequest) Reset() {
+	*x = WatchMomentalChangesRequest{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMomentalChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMomentalChangesRequest) ProtoMessage() {}
+
+func (x *WatchMomentalChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMomentalChangesRequest.ProtoReflect.Descriptor instead.
+func (*WatchMomentalChangesRequest) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{11}
+}
+
+type MomentalChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          MomentalChange_Type    `protobuf:"varint,1,opt,name=type,proto3,enum=stoloto.v1.MomentalChange_Type" json:"type,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Card          *MomentCard            `protobuf:"bytes,3,opt,name=card,proto3" json:"card,omitempty"`
+	Previous      *MomentCard            `protobuf:"bytes,4,opt,name=previous,proto3" json:"previous,omitempty"`
+	Fields        []string               `protobuf:"bytes,5,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MomentalChange) Reset() {
+	*x = MomentalChange{}
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MomentalChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MomentalChange) ProtoMessage() {}
+
+func (x *MomentalChange) ProtoReflect() protoreflect.Message {
+	mi := &file_stoloto_v1_stoloto_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MomentalChange.ProtoReflect.Descriptor instead.
+func (*MomentalChange) Descriptor() ([]byte, []int) {
+	return file_stoloto_v1_stoloto_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MomentalChange) GetType() MomentalChange_Type {
+	if x != nil {
+		return x.Type
+	}
+	return MomentalChange_TYPE_UNSPECIFIED
+}
+
+func (x *MomentalChange) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *MomentalChange) GetCard() *MomentCard {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *MomentalChange) GetPrevious() *MomentCard {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *MomentalChange) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+var File_stoloto_v1_stoloto_proto protoreflect.FileDescriptor
+
+const file_stoloto_v1_stoloto_proto_rawDesc = "" +
+	"\n" +
+	"\x18stoloto/v1/stoloto.proto\x12\n" +
+	"stoloto.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc7\x01\n" +
+	"\x04Game\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"sales_open\x18\x04 \x01(\bR\tsalesOpen\x123\n" +
+	"\fcurrent_draw\x18\x05 \x01(\v2\x10.stoloto.v1.DrawR\vcurrentDraw\x12-\n" +
+	"\tlast_draw\x18\x06 \x01(\v2\x10.stoloto.v1.DrawR\blastDraw\"\xc6\x02\n" +
+	"\x04Draw\x12\x12\n" +
+	"\x04game\x18\x01 \x01(\tR\x04game\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\x12.\n" +
+	"\x04date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12.\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x16.stoloto.v1.DrawStatusR\x06status\x12\x1f\n" +
+	"\vsuper_prize\x18\x05 \x01(\x03R\n" +
+	"superPrize\x12\x18\n" +
+	"\anumbers\x18\x06 \x03(\x05R\anumbers\x121\n" +
+	"\x06prizes\x18\a \x03(\v2\x19.stoloto.v1.PrizeCategoryR\x06prizes\x12#\n" +
+	"\rtotal_winners\x18\b \x01(\x05R\ftotalWinners\x12\x1f\n" +
+	"\vtotal_prize\x18\t \x01(\x03R\n" +
+	"totalPrize\"w\n" +
+	"\rPrizeCategory\x12\x1a\n" +
+	"\bcategory\x18\x01 \x01(\x05R\bcategory\x12\x18\n" +
+	"\amatched\x18\x02 \x01(\tR\amatched\x12\x18\n" +
+	"\awinners\x18\x03 \x01(\x05R\awinners\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\"\x11\n" +
+	"\x0fGetGamesRequest\":\n" +
+	"\x10GetGamesResponse\x12&\n" +
+	"\x05games\x18\x01 \x03(\v2\x10.stoloto.v1.GameR\x05games\"<\n" +
+	"\x0eGetDrawRequest\x12\x12\n" +
+	"\x04game\x18\x01 \x01(\tR\x04game\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\"*\n" +
+	"\x14GetLatestDrawRequest\x12\x12\n" +
+	"\x04game\x18\x01 \x01(\tR\x04game\"J\n" +
+	"\x10ListDrawsRequest\x12\x12\n" +
+	"\x04game\x18\x01 \x01(\tR\x04game\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\x05R\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\x05R\x02to\")\n" +
+	"\x11WatchDrawsRequest\x12\x14\n" +
+	"\x05games\x18\x01 \x03(\tR\x05games\"\xa4\x01\n" +
+	"\tDrawEvent\x12.\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1a.stoloto.v1.DrawEvent.TypeR\x04type\x12$\n" +
+	"\x04draw\x18\x02 \x01(\v2\x10.stoloto.v1.DrawR\x04draw\"A\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vTYPE_OPENED\x10\x01\x12\x12\n" +
+	"\x0eTYPE_COMPLETED\x10\x02\"\x9e\x01\n" +
+	"\n" +
+	"MomentCard\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x03R\x05price\x12\x1b\n" +
+	"\tmax_prize\x18\x04 \x01(\x03R\bmaxPrize\x12\x1c\n" +
+	"\tavailable\x18\x05 \x01(\bR\tavailable\x12\x1b\n" +
+	"\timage_url\x18\x06 \x01(\tR\bimageUrl\"\x1d\n" +
+	"\x1bWatchMomentalChangesRequest\"\xbf\x02\n" +
+	"\x0eMomentalChange\x123\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1f.stoloto.v1.MomentalChange.TypeR\x04type\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12*\n" +
+	"\x04card\x18\x03 \x01(\v2\x16.stoloto.v1.MomentCardR\x04card\x122\n" +
+	"\bprevious\x18\x04 \x01(\v2\x16.stoloto.v1.MomentCardR\bprevious\x12\x16\n" +
+	"\x06fields\x18\x05 \x03(\tR\x06fields\"P\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"TYPE_ADDED\x10\x01\x12\x10\n" +
+	"\fTYPE_REMOVED\x10\x02\x12\x10\n" +
+	"\fTYPE_CHANGED\x10\x03*Z\n" +
+	"\n" +
+	"DrawStatus\x12\x1b\n" +
+	"\x17DRAW_STATUS_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10DRAW_STATUS_OPEN\x10\x01\x12\x19\n" +
+	"\x15DRAW_STATUS_COMPLETED\x10\x022\xb9\x03\n" +
+	"\x0eStolotoService\x12E\n" +
+	"\bGetGames\x12\x1b.stoloto.v1.GetGamesRequest\x1a\x1c.stoloto.v1.GetGamesResponse\x127\n" +
+	"\aGetDraw\x12\x1a.stoloto.v1.GetDrawRequest\x1a\x10.stoloto.v1.Draw\x12C\n" +
+	"\rGetLatestDraw\x12 .stoloto.v1.GetLatestDrawRequest\x1a\x10.stoloto.v1.Draw\x12=\n" +
+	"\tListDraws\x12\x1c.stoloto.v1.ListDrawsRequest\x1a\x10.stoloto.v1.Draw0\x01\x12D\n" +
+	"\n" +
+	"WatchDraws\x12\x1d.stoloto.v1.WatchDrawsRequest\x1a\x15.stoloto.v1.DrawEvent0\x01\x12]\n" +
+	"\x14WatchMomentalChanges\x12'.stoloto.v1.WatchMomentalChangesRequest\x1a\x1a.stoloto.v1.MomentalChange0\x01B#Z!DOUPIG/proto/stoloto/v1;stolotov1b\x06proto3"
+
+var (
+	file_stoloto_v1_stoloto_proto_rawDescOnce sync.Once
+	file_stoloto_v1_stoloto_proto_rawDescData []byte
+)
+
+func file_stoloto_v1_stoloto_proto_rawDescGZIP() []byte {
+	file_stoloto_v1_stoloto_proto_rawDescOnce.Do(func() {
+		file_stoloto_v1_stoloto_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_stoloto_v1_stoloto_proto_rawDesc), len(file_stoloto_v1_stoloto_proto_rawDesc)))
+	})
+	return file_stoloto_v1_stoloto_proto_rawDescData
+}
+
+var file_stoloto_v1_stoloto_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_stoloto_v1_stoloto_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_stoloto_v1_stoloto_proto_goTypes = []any{
+	(DrawStatus)(0),                     // 0: stoloto.v1.DrawStatus
+	(DrawEvent_Type)(0),                 // 1: stoloto.v1.DrawEvent.Type
+	(MomentalChange_Type)(0),            // 2: stoloto.v1.MomentalChange.Type
+	(*Game)(nil),                        // 3: stoloto.v1.Game
+	(*Draw)(nil),                        // 4: stoloto.v1.Draw
+	(*PrizeCategory)(nil),               // 5: stoloto.v1.PrizeCategory
+	(*GetGamesRequest)(nil),             // 6: stoloto.v1.GetGamesRequest
+	(*GetGamesResponse)(nil),            // 7: stoloto.v1.GetGamesResponse
+	(*GetDrawRequest)(nil),              // 8: stoloto.v1.GetDrawRequest
+	(*GetLatestDrawRequest)(nil),        // 9: stoloto.v1.GetLatestDrawRequest
+	(*ListDrawsRequest)(nil),            // 10: stoloto.v1.ListDrawsRequest
+	(*WatchDrawsRequest)(nil),           // 11: stoloto.v1.WatchDrawsRequest
+	(*DrawEvent)(nil),                   // 12: stoloto.v1.DrawEvent
+	(*MomentCard)(nil),                  // 13: stoloto.v1.MomentCard
+	(*WatchMomentalChangesRequest)(nil), // 14: stoloto.v1.WatchMomentalChangesRequest
+	(*MomentalChange)(nil),              // 15: stoloto.v1.MomentalChange
+	(*timestamppb.Timestamp)(nil),       // 16: google.protobuf.Timestamp
+}
+var file_stoloto_v1_stoloto_proto_depIdxs = []int32{
+	4,  // 0: stoloto.v1.Game.current_draw:type_name -> stoloto.v1.Draw
+	4,  // 1: stoloto.v1.Game.last_draw:type_name -> stoloto.v1.Draw
+	16, // 2: stoloto.v1.Draw.date:type_name -> google.protobuf.Timestamp
+	0,  // 3: stoloto.v1.Draw.status:type_name -> stoloto.v1.DrawStatus
+	5,  // 4: stoloto.v1.Draw.prizes:type_name -> stoloto.v1.PrizeCategory
+	3,  // 5: stoloto.v1.GetGamesResponse.games:type_name -> stoloto.v1.Game
+	1,  // 6: stoloto.v1.DrawEvent.type:type_name -> stoloto.v1.DrawEvent.Type
+	4,  // 7: stoloto.v1.DrawEvent.draw:type_name -> stoloto.v1.Draw
+	2,  // 8: stoloto.v1.MomentalChange.type:type_name -> stoloto.v1.MomentalChange.Type
+	16, // 9: stoloto.v1.MomentalChange.time:type_name -> google.protobuf.Timestamp
+	13, // 10: stoloto.v1.MomentalChange.card:type_name -> stoloto.v1.MomentCard
+	13, // 11: stoloto.v1.MomentalChange.previous:type_name -> stoloto.v1.MomentCard
+	6,  // 12: stoloto.v1.StolotoService.GetGames:input_type -> stoloto.v1.GetGamesRequest
+	8,  // 13: stoloto.v1.StolotoService.GetDraw:input_type -> stoloto.v1.GetDrawRequest
+	9,  // 14: stoloto.v1.StolotoService.GetLatestDraw:input_type -> stoloto.v1.GetLatestDrawRequest
+	10, // 15: stoloto.v1.StolotoService.ListDraws:input_type -> stoloto.v1.ListDrawsRequest
+	11, // 16: stoloto.v1.StolotoService.WatchDraws:input_type -> stoloto.v1.WatchDrawsRequest
+	14, // 17: stoloto.v1.StolotoService.WatchMomentalChanges:input_type -> stoloto.v1.WatchMomentalChangesRequest
+	7,  // 18: stoloto.v1.StolotoService.GetGames:output_type -> stoloto.v1.GetGamesResponse
+	4,  // 19: stoloto.v1.StolotoService.GetDraw:output_type -> stoloto.v1.Draw
+	4,  // 20: stoloto.v1.StolotoService.GetLatestDraw:output_type -> stoloto.v1.Draw
+	4,  // 21: stoloto.v1.StolotoService.ListDraws:output_type -> stoloto.v1.Draw
+	12, // 22: stoloto.v1.StolotoService.WatchDraws:output_type -> stoloto.v1.DrawEvent
+	15, // 23: stoloto.v1.StolotoService.WatchMomentalChanges:output_type -> stoloto.v1.MomentalChange
+	18, // [18:24] is the sub-list for method output_type
+	12, // [12:18] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_stoloto_v1_stoloto_proto_init() }
+func file_stoloto_v1_stoloto_proto_init() {
+	if File_stoloto_v1_stoloto_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stoloto_v1_stoloto_proto_rawDesc), len(file_stoloto_v1_stoloto_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_stoloto_v1_stoloto_proto_goTypes,
+		DependencyIndexes: file_stoloto_v1_stoloto_proto_depIdxs,
+		EnumInfos:         file_stoloto_v1_stoloto_proto_enumTypes,
+		MessageInfos:      file_stoloto_v1_stoloto_proto_msgTypes,
+	}.Build()
+	File_stoloto_v1_stoloto_proto = out.File
+	file_stoloto_v1_stoloto_proto_goTypes = nil
+	file_stoloto_v1_stoloto_proto_depIdxs = nil
+}
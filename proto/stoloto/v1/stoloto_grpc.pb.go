@@ -0,0 +1,321 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: stoloto/v1/stoloto.proto
+
+package stolotov1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	StolotoService_GetGames_FullMethodName             = "/stoloto.v1.StolotoService/GetGames"
+	StolotoService_GetDraw_FullMethodName              = "/stoloto.v1.StolotoService/GetDraw"
+	StolotoService_GetLatestDraw_FullMethodName        = "/stoloto.v1.StolotoService/GetLatestDraw"
+	StolotoService_ListDraws_FullMethodName            = "/stoloto.v1.StolotoService/ListDraws"
+	StolotoService_WatchDraws_FullMethodName           = "/stoloto.v1.StolotoService/WatchDraws"
+	StolotoService_WatchMomentalChanges_FullMethodName = "/stoloto.v1.StolotoService/WatchMomentalChanges"
+)
+
+// StolotoServiceClient is the client API for StolotoService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StolotoServiceClient interface {
+	GetGames(ctx context.Context, in *GetGamesRequest, opts ...grpc.CallOption) (*GetGamesResponse, error)
+	GetDraw(ctx context.Context, in *GetDrawRequest, opts ...grpc.CallOption) (*Draw, error)
+	GetLatestDraw(ctx context.Context, in *GetLatestDrawRequest, opts ...grpc.CallOption) (*Draw, error)
+	ListDraws(ctx context.Context, in *ListDrawsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Draw], error)
+	WatchDraws(ctx context.Context, in *WatchDrawsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DrawEvent], error)
+	WatchMomentalChanges(ctx context.Context, in *WatchMomentalChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MomentalChange], error)
+}
+
+type stolotoServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStolotoServiceClient(cc grpc.ClientConnInterface) StolotoServiceClient {
+	return &stolotoServiceClient{cc}
+}
+
+func (c *stolotoServiceClient) GetGames(ctx context.Context, in *GetGamesRequest, opts ...grpc.CallOption) (*GetGamesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetGamesResponse)
+	err := c.cc.Invoke(ctx, StolotoService_GetGames_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stolotoServiceClient) GetDraw(ctx context.Context, in *GetDrawRequest, opts ...grpc.CallOption) (*Draw, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Draw)
+	err := c.cc.Invoke(ctx, StolotoService_GetDraw_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stolotoServiceClient) GetLatestDraw(ctx context.Context, in *GetLatestDrawRequest, opts ...grpc.CallOption) (*Draw, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Draw)
+	err := c.cc.Invoke(ctx, StolotoService_GetLatestDraw_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stolotoServiceClient) ListDraws(ctx context.Context, in *ListDrawsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Draw], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StolotoService_ServiceDesc.Streams[0], StolotoService_ListDraws_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListDrawsRequest, Draw]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StolotoService_ListDrawsClient = grpc.ServerStreamingClient[Draw]
+
+func (c *stolotoServiceClient) WatchDraws(ctx context.Context, in *WatchDrawsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DrawEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StolotoService_ServiceDesc.Streams[1], StolotoService_WatchDraws_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchDrawsRequest, DrawEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StolotoService_WatchDrawsClient = grpc.ServerStreamingClient[DrawEvent]
+
+func (c *stolotoServiceClient) WatchMomentalChanges(ctx context.Context, in *WatchMomentalChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MomentalChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StolotoService_ServiceDesc.Streams[2], StolotoService_WatchMomentalChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMomentalChangesRequest, MomentalChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StolotoService_WatchMomentalChangesClient = grpc.ServerStreamingClient[MomentalChange]
+
+// StolotoServiceServer is the server API for StolotoService service.
+// All implementations must embed UnimplementedStolotoServiceServer
+// for forward compatibility.
+type StolotoServiceServer interface {
+	GetGames(context.Context, *GetGamesRequest) (*GetGamesResponse, error)
+	GetDraw(context.Context, *GetDrawRequest) (*Draw, error)
+	GetLatestDraw(context.Context, *GetLatestDrawRequest) (*Draw, error)
+	ListDraws(*ListDrawsRequest, grpc.ServerStreamingServer[Draw]) error
+	WatchDraws(*WatchDrawsRequest, grpc.ServerStreamingServer[DrawEvent]) error
+	WatchMomentalChanges(*WatchMomentalChangesRequest, grpc.ServerStreamingServer[MomentalChange]) error
+	mustEmbedUnimplementedStolotoServiceServer()
+}
+
+// UnimplementedStolotoServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStolotoServiceServer struct{}
+
+func (UnimplementedStolotoServiceServer) GetGames(context.Context, *GetGamesRequest) (*GetGamesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGames not implemented")
+}
+func (UnimplementedStolotoServiceServer) GetDraw(context.Context, *GetDrawRequest) (*Draw, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDraw not implemented")
+}
+func (UnimplementedStolotoServiceServer) GetLatestDraw(context.Context, *GetLatestDrawRequest) (*Draw, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestDraw not implemented")
+}
+func (UnimplementedStolotoServiceServer) ListDraws(*ListDrawsRequest, grpc.ServerStreamingServer[Draw]) error {
+	return status.Errorf(codes.Unimplemented, "method ListDraws not implemented")
+}
+func (UnimplementedStolotoServiceServer) WatchDraws(*WatchDrawsRequest, grpc.ServerStreamingServer[DrawEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDraws not implemented")
+}
+func (UnimplementedStolotoServiceServer) WatchMomentalChanges(*WatchMomentalChangesRequest, grpc.ServerStreamingServer[MomentalChange]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMomentalChanges not implemented")
+}
+func (UnimplementedStolotoServiceServer) mustEmbedUnimplementedStolotoServiceServer() {}
+func (UnimplementedStolotoServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeStolotoServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StolotoServiceServer will
+// result in compilation errors.
+type UnsafeStolotoServiceServer interface {
+	mustEmbedUnimplementedStolotoServiceServer()
+}
+
+func RegisterStolotoServiceServer(s grpc.ServiceRegistrar, srv StolotoServiceServer) {
+	// If the following call pancis, it indicates UnimplementedStolotoServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&StolotoService_ServiceDesc, srv)
+}
+
+func _StolotoService_GetGames_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGamesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StolotoServiceServer).GetGames(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StolotoService_GetGames_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StolotoServiceServer).GetGames(ctx, req.(*GetGamesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StolotoService_GetDraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDrawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StolotoServiceServer).GetDraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StolotoService_GetDraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StolotoServiceServer).GetDraw(ctx, req.(*GetDrawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StolotoService_GetLatestDraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestDrawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StolotoServiceServer).GetLatestDraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StolotoService_GetLatestDraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StolotoServiceServer).GetLatestDraw(ctx, req.(*GetLatestDrawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StolotoService_ListDraws_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListDrawsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StolotoServiceServer).ListDraws(m, &grpc.GenericServerStream[ListDrawsRequest, Draw]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StolotoService_ListDrawsServer = grpc.ServerStreamingServer[Draw]
+
+func _StolotoService_WatchDraws_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDrawsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StolotoServiceServer).WatchDraws(m, &grpc.GenericServerStream[WatchDrawsRequest, DrawEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StolotoService_WatchDrawsServer = grpc.ServerStreamingServer[DrawEvent]
+
+func _StolotoService_WatchMomentalChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMomentalChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StolotoServiceServer).WatchMomentalChanges(m, &grpc.GenericServerStream[WatchMomentalChangesRequest, MomentalChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StolotoService_WatchMomentalChangesServer = grpc.ServerStreamingServer[MomentalChange]
+
+// StolotoService_ServiceDesc is the grpc.ServiceDesc for StolotoService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StolotoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "stoloto.v1.StolotoService",
+	HandlerType: (*StolotoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetGames",
+			Handler:    _StolotoService_GetGames_Handler,
+		},
+		{
+			MethodName: "GetDraw",
+			Handler:    _StolotoService_GetDraw_Handler,
+		},
+		{
+			MethodName: "GetLatestDraw",
+			Handler:    _StolotoService_GetLatestDraw_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListDraws",
+			Handler:       _StolotoService_ListDraws_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchDraws",
+			Handler:       _StolotoService_WatchDraws_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchMomentalChanges",
+			Handler:       _StolotoService_WatchMomentalChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "stoloto/v1/stoloto.proto",
+}
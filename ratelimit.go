@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit — параметры token bucket: скорость пополнения и ёмкость.
+// Нулевой PerMinute означает отсутствие ограничения.
+type RateLimit struct {
+	PerMinute float64 `json:"perMinute"`
+	Burst     int     `json:"burst"`
+}
+
+// RouteRateLimit — лимиты для одного маршрута: по IP клиента и по API-ключу
+type RouteRateLimit struct {
+	PerIP  RateLimit `json:"perIP"`
+	PerKey RateLimit `json:"perKey"`
+}
+
+// RateLimitConfig — лимиты по префиксу пути входящего запроса, например "/api/"
+// или "/api/draw/latest". Запросы, не попавшие ни под один префикс, не ограничиваются.
+type RateLimitConfig struct {
+	Enabled bool                      `json:"enabled"`
+	Routes  map[string]RouteRateLimit `json:"routes"`
+	// TrustProxyHeaders включает определение IP по X-Forwarded-For,
+	// если прокси стоит за балансировщиком
+	TrustProxyHeaders bool `json:"trustProxyHeaders"`
+}
+
+// RateLimitResult — состояние бакета после попытки взять токен
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset — время до полного восстановления бакета
+	Reset time.Duration
+	// RetryAfter — время до появления следующего токена, если запрос отклонён
+	RetryAfter time.Duration
+}
+
+// RateLimitStore хранит состояние бакетов. По умолчанию используется
+// memoryRateLimitStore; для нескольких экземпляров прокси достаточно
+// реализовать интерфейс поверх общего хранилища (Redis и т.п.).
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+// rateLimitStore используется middleware; заменяется до запуска сервера
+var rateLimitStore RateLimitStore = newMemoryRateLimitStore()
+
+// withRateLimit ограничивает частоту запросов клиента. Проверяются оба бакета:
+// по IP и, если клиент передал API-ключ, по ключу. В заголовки RateLimit-*
+// попадает более строгий из них.
+func withRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.RateLimit.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		route, ok := longestPrefix(cfg.RateLimit.Routes, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		checks := []rateLimitCheck{{"ip:" + clientIP(r), route.PerIP}}
+		if key, ok := authenticatedKey(r); ok {
+			checks = append(checks, rateLimitCheck{"key:" + key.ID, route.PerKey})
+		} else if key := clientAPIKey(r); key != "" {
+			// Аутентификация выключена: считаем по хешу ключа, чтобы не держать его в памяти
+			checks = append(checks, rateLimitCheck{"key:" + hashAPIKey(key), route.PerKey})
+		}
+
+		if !takeRateLimit(w, r, checks) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitUnauthorized списывает токен из бакета IP за запрос, не прошедший
+// аутентификацию. withRateLimit стоит за withAuth и такие запросы не видит,
+// а без лимита API-ключи можно перебирать. Возвращает false, если лимит
+// исчерпан и клиенту уже отдан 429.
+func rateLimitUnauthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !cfg.RateLimit.Enabled {
+		return true
+	}
+	route, ok := longestPrefix(cfg.RateLimit.Routes, r.URL.Path)
+	if !ok {
+		return true
+	}
+	return takeRateLimit(w, r, []rateLimitCheck{{"ip:" + clientIP(r), route.PerIP}})
+}
+
+type rateLimitCheck struct {
+	key   string
+	limit RateLimit
+}
+
+// takeRateLimit берёт по токену из каждого бакета и выставляет заголовки
+// RateLimit-* по самому строгому. Если какой-то бакет пуст, отвечает 429
+// и возвращает false.
+func takeRateLimit(w http.ResponseWriter, r *http.Request, checks []rateLimitCheck) bool {
+	now := time.Now()
+	var strictest *RateLimitResult
+	var strictestLimit RateLimit
+
+	for _, c := range checks {
+		if c.limit.PerMinute <= 0 {
+			continue
+		}
+
+		res, err := rateLimitStore.Take(r.Context(), c.key, c.limit, now)
+		if err != nil {
+			// Недоступность хранилища не должна ронять прокси
+			log.Printf("Rate limit store error for %s: %v", c.key, err)
+			continue
+		}
+
+		if strictest == nil || !res.Allowed || (strictest.Allowed && res.Remaining < strictest.Remaining) {
+			strictest, strictestLimit = &res, c.limit
+		}
+		if !res.Allowed {
+			break
+		}
+	}
+
+	if strictest != nil {
+		setRateLimitHeaders(w, strictestLimit, *strictest)
+		if !strictest.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(strictest.RetryAfter)))
+			sendError(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return false
+		}
+	}
+	return true
+}
+
+// setRateLimitHeaders сообщает ёмкость, с которой реально работает бакет:
+// нулевой Burst означает бакет на один токен
+func setRateLimitHeaders(w http.ResponseWriter, limit RateLimit, res RateLimitResult) {
+	burst := max(limit.Burst, 1)
+	window := float64(burst) / limit.PerMinute * 60
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(burst))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+	w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", burst, int(math.Ceil(window))))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// clientIP возвращает IP клиента. При TrustProxyHeaders берётся последний адрес
+// из X-Forwarded-For — его добавил наш балансировщик, и подделать его клиент не может.
+func clientIP(r *http.Request) string {
+	if cfg.RateLimit.TrustProxyHeaders {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			parts := strings.Split(xff, ",")
+			return strings.TrimSpace(parts[len(parts)-1])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientAPIKey извлекает API-ключ из заголовка X-API-Key или параметра api_key
+func clientAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// memoryRateLimitStore держит бакеты в памяти процесса
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+func (s *memoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	rate := limit.PerMinute / 60
+	burst := float64(max(limit.Burst, 1))
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	res := RateLimitResult{Allowed: b.tokens >= 1}
+	if res.Allowed {
+		b.tokens--
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = secondsToDuration((burst - b.tokens) / rate)
+	b.full = now.Add(res.Reset)
+
+	return res, nil
+}
+
+// sweep раз в минуту удаляет бакеты, которые успели полностью восстановиться:
+// их состояние совпадает с новым бакетом
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy задаёт правила повторных запросов к апстриму
+type RetryPolicy struct {
+	MaxAttempts int      `json:"maxAttempts"`
+	BaseDelay   Duration `json:"baseDelay"`
+	MaxDelay    Duration `json:"maxDelay"`
+	// Budget ограничивает суммарное время всех попыток, включая паузы между ними
+	Budget Duration `json:"budget"`
+}
+
+// RetryConfig — политика по умолчанию и переопределения по префиксу пути апстрима,
+// например "/service/draws/" или "/cms/api/moment-cards-section"
+type RetryConfig struct {
+	Default   RetryPolicy            `json:"default"`
+	Endpoints map[string]RetryPolicy `json:"endpoints"`
+}
+
+// policyFor выбирает политику с самым длинным совпавшим префиксом
+func (c RetryConfig) policyFor(path string) RetryPolicy {
+	if p, ok := longestPrefix(c.Endpoints, path); ok {
+		return p.withDefaults(c.Default)
+	}
+	return c.Default
+}
+
+func (p RetryPolicy) withDefaults(def RetryPolicy) RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.BaseDelay == 0 {
+		p.BaseDelay = def.BaseDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+	if p.Budget == 0 {
+		p.Budget = def.Budget
+	}
+	return p
+}
+
+// doWithRetry выполняет запрос, повторяя его при сетевых ошибках, 429 и 5xx.
+// Повторяются только идемпотентные методы. Все попытки укладываются в Budget,
+// отсчитываемый от ctx входящего запроса: если следующая пауза не помещается
+// в оставшееся время, возвращается результат последней попытки.
+func doWithRetry(ctx context.Context, client *http.Client, req *http.Request, policy RetryPolicy) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return client.Do(req.WithContext(ctx))
+	}
+
+	var cancel context.CancelFunc
+	if policy.Budget > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(policy.Budget))
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	attempts := max(policy.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req.Clone(ctx))
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := backoffDelay(policy, attempt)
+		if err == nil {
+			if ra, ok := retryAfter(resp, time.Now()); ok {
+				delay = ra
+			}
+		}
+
+		if attempt >= attempts || !fitsBudget(ctx, delay) || isPermanentError(err) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			// Дочитываем тело, чтобы соединение вернулось в пул
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		log.Printf("Upstream %s attempt %d/%d failed (%s), retrying in %v", req.URL.Path, attempt, attempts, reason, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			cancel()
+			return nil, fmt.Errorf("retry budget exhausted after %d attempts: %w", attempt, ctx.Err())
+		}
+	}
+}
+
+// isPermanentError — ошибки, которые не исправятся повтором: переполненная
+// очередь бюджета (повтор только усугубит её) и отсутствие записи в кассете
+func isPermanentError(err error) bool {
+	return errors.Is(err, errUpstreamQueueFull) || errors.Is(err, errNoRecording)
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// backoffDelay — экспоненциальная задержка с «равным» джиттером:
+// половина интервала фиксирована, вторая половина случайна
+func backoffDelay(policy RetryPolicy, attempt int) time.Duration {
+	d := time.Duration(policy.BaseDelay) << (attempt - 1)
+	if d <= 0 || d > time.Duration(policy.MaxDelay) {
+		d = time.Duration(policy.MaxDelay)
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// retryAfter разбирает заголовок Retry-After в секундах или в виде HTTP-даты
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+func fitsBudget(ctx context.Context, delay time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > delay
+}
+
+// cancelOnClose освобождает контекст бюджета только после того,
+// как вызывающий код дочитал и закрыл тело ответа
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"DOUPIG/fakestoloto"
+)
+
+func TestRetryRecoversFromTransientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		status   int
+		requests int
+	}{
+		{"recovers", 2, http.StatusOK, 3},
+		{"gives up after max attempts", 5, http.StatusBadGateway, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, proxy := startProxy(t, nil)
+			fake.InjectFault(fakestoloto.Fault{Path: "/service/games/", Status: http.StatusServiceUnavailable, Times: tt.failures})
+
+			resp := getJSON(t, proxy.URL+"/api/v1/games", nil)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if n := countRequests(fake, "/service/games/"); n != tt.requests {
+				t.Fatalf("upstream requests = %d, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestRetrySkipsClientErrors(t *testing.T) {
+	fake, proxy := startProxy(t, nil)
+	fake.InjectFault(fakestoloto.Fault{Path: "/service/games/", Status: http.StatusBadRequest})
+
+	getJSON(t, proxy.URL+"/api/v1/games", nil)
+	if n := countRequests(fake, "/service/games/"); n != 1 {
+		t.Fatalf("upstream requests = %d, want 1", n)
+	}
+}
+
+// countRequests считает запросы к фейку, путь которых содержит part
+func countRequests(fake *fakestoloto.Server, part string) int {
+	n := 0
+	for _, r := range fake.Requests() {
+		if strings.Contains(r, part) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	httpSwagger "github.com/swaggo/http-swagger"
+)
+
+// registerRoutes регистрирует все маршруты прокси. Шаблоны задаются вместе
+// с методом (GET покрывает и HEAD); ответы на неизвестный путь или чужой
+// метод формирует withRouteErrors.
+func registerRoutes(mux *http.ServeMux) {
+	// /api/v1/... и /api/... для текущей версии
+	registerAPIVersions(mux)
+
+	// Ответы Stoloto как есть
+	mux.HandleFunc("GET /api/raw/games", handleDraws)
+	mux.HandleFunc("GET /api/raw/games/{name}/draws/{number}", handleDraw)
+	mux.HandleFunc("GET /api/raw/games/{name}/draws/latest", handleDrawLatest)
+	mux.HandleFunc("GET /api/raw/games/{name}/draws/prelatest", handleDrawPreLatest)
+	mux.HandleFunc("GET /api/raw/momental", handleMomentalCards)
+
+	mux.HandleFunc("GET /api/export", handleExport)
+	mux.HandleFunc("GET /api/calendar.ics", handleCalendar)
+	mux.HandleFunc("GET /api/feed.atom", handleFeedAtom)
+	mux.HandleFunc("GET /api/feed.rss", handleFeedRSS)
+
+	// Старые маршруты с параметрами в query string, оставлены для совместимости
+	mux.Handle("GET /api/draws/{$}", deprecatedAlias(handleDraws, "/api/raw/games"))
+	mux.Handle("GET /api/draw/{$}", deprecatedAlias(handleDraw, "/api/raw/games/{name}/draws/{number}"))
+	mux.Handle("GET /api/draw/latest", deprecatedAlias(handleDrawLatest, "/api/raw/games/{name}/draws/latest"))
+	mux.Handle("GET /api/draw/prelatest", deprecatedAlias(handleDrawPreLatest, "/api/raw/games/{name}/draws/prelatest"))
+	mux.Handle("GET /api/draw/momental", deprecatedAlias(handleMomentalCards, "/api/raw/momental"))
+
+	mux.HandleFunc("GET /graphql", handleGraphQL)
+	mux.HandleFunc("POST /graphql", handleGraphQL)
+
+	// Управление ключами доступно только при включённой аутентификации,
+	// иначе эндпоинт был бы открыт всем
+	if cfg.Auth.Enabled {
+		mux.HandleFunc("GET /api/admin/keys", handleAdminKeys)
+		mux.HandleFunc("POST /api/admin/keys", handleAdminKeys)
+		mux.HandleFunc("DELETE /api/admin/keys", handleAdminKeys)
+	}
+
+	mux.HandleFunc("GET /metrics", handleMetrics)
+
+	// Swagger UI
+	mux.Handle("GET /swagger/", httpSwagger.WrapHandler)
+}
+
+// pathParam берёт параметр из пути, а для старых маршрутов — из query string
+func pathParam(r *http.Request, name string) string {
+	if v := r.PathValue(name); v != "" {
+		return v
+	}
+	return r.URL.Query().Get(name)
+}
+
+// deprecatedAlias помечает старый маршрут заголовком Deprecation и ссылкой
+// на новый. В шаблоне successor {name} и {number} подставляются из запроса;
+// если параметров не хватает, ссылка не выставляется.
+func deprecatedAlias(h http.HandlerFunc, successor string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+
+		link, complete := successor, true
+		for _, name := range []string{"name", "number"} {
+			placeholder := "{" + name + "}"
+			if !strings.Contains(link, placeholder) {
+				continue
+			}
+			v := r.URL.Query().Get(name)
+			complete = complete && v != ""
+			link = strings.ReplaceAll(link, placeholder, url.PathEscape(v))
+		}
+		if complete {
+			w.Header().Set("Link", "<"+link+`>; rel="successor-version"`)
+		}
+
+		h(w, r)
+	})
+}
+
+// probeMethods — методы, которые проверяются при формировании заголовка Allow
+var probeMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// withRouteErrors отвечает JSON-ошибкой, если маршрут не найден (404) или
+// не поддерживает метод (405 с заголовком Allow)
+func withRouteErrors(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		var allowed []string
+		for _, method := range probeMethods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != "" {
+				allowed = append(allowed, method)
+			}
+		}
+
+		if len(allowed) == 0 {
+			sendError(w, "Not found: "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	})
+}
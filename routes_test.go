@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRouteErrors(t *testing.T) {
+	_, proxy := startProxy(t, nil)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		allow  string
+	}{
+		{http.MethodGet, "/api/v1/nope", http.StatusNotFound, ""},
+		{http.MethodGet, "/nope", http.StatusNotFound, ""},
+		{http.MethodPost, "/api/v1/games", http.StatusMethodNotAllowed, "GET, HEAD"},
+		{http.MethodDelete, "/api/raw/games", http.StatusMethodNotAllowed, "GET, HEAD"},
+		{http.MethodPut, "/graphql", http.StatusMethodNotAllowed, "GET, HEAD, POST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, proxy.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := resp.Header.Get("Allow"); got != tt.allow {
+				t.Fatalf("Allow = %q, want %q", got, tt.allow)
+			}
+			if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Fatalf("Content-Type = %q, want JSON error", ct)
+			}
+			var body ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" || body.Success {
+				t.Fatalf("error body = %+v, %v", body, err)
+			}
+		})
+	}
+}
+
+func TestDeprecatedAliasLinksSuccessor(t *testing.T) {
+	_, proxy := startProxy(t, nil)
+
+	resp := getJSON(t, proxy.URL+"/api/draw/?name=6x45&number=100", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if resp.Header.Get("Deprecation") != "true" {
+		t.Fatal("missing Deprecation header")
+	}
+	if want := `</api/raw/games/6x45/draws/100>; rel="successor-version"`; resp.Header.Get("Link") != want {
+		t.Fatalf("Link = %q, want %q", resp.Header.Get("Link"), want)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+// gameRules — правила числовых лотерей: сколько чисел выбирается и из какого
+// диапазона в каждом поле билета. Stoloto их не отдаёт, поэтому таблица своя;
+// для бинго-лотерей правил нет.
+var gameRules = map[string][]ruleField{
+	"6x45":     {{Pick: 6, From: 45}},
+	"7x49":     {{Pick: 7, From: 49}},
+	"5x36plus": {{Pick: 5, From: 36}, {Pick: 1, From: 4}},
+	"4x20":     {{Pick: 4, From: 20}, {Pick: 4, From: 20}},
+	"12x24":    {{Pick: 12, From: 24}},
+}
+
+// ruleField — поле билета: Pick чисел из 1..From
+type ruleField struct {
+	Pick int `json:"pick"`
+	From int `json:"from"`
+}
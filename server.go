@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ServerConfig — параметры HTTP-сервера прокси
+type ServerConfig struct {
+	Addr              string   `json:"addr"`
+	ReadTimeout       Duration `json:"readTimeout"`
+	ReadHeaderTimeout Duration `json:"readHeaderTimeout"`
+	// WriteTimeout должен быть больше бюджета повторов (retry.default.budget),
+	// иначе медленный апстрим оборвёт ответ на середине
+	WriteTimeout   Duration `json:"writeTimeout"`
+	IdleTimeout    Duration `json:"idleTimeout"`
+	MaxHeaderBytes int      `json:"maxHeaderBytes"`
+	// PublicHost — хост для Swagger UI и логов, если сервер доступен под другим именем
+	PublicHost string `json:"publicHost"`
+	// ShutdownTimeout — сколько ждать завершения запросов и фоновых задач после SIGINT/SIGTERM
+	ShutdownTimeout Duration `json:"shutdownTimeout"`
+}
+
+// workerGroup — фоновые задачи (опрос апстрима, обновление кеша и т.п.).
+// Их контекст отменяется при остановке сервера.
+type workerGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// mu делает запуск задачи и остановку атомарными: после Stop ни одна
+	// задача не начнётся, а wg.Add не выполнится параллельно с wg.Wait
+	mu      sync.Mutex
+	stopped bool
+}
+
+var background = newWorkerGroup()
+
+func newWorkerGroup() *workerGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workerGroup{ctx: ctx, cancel: cancel}
+}
+
+// Go запускает fn в отдельной горутине; fn должна завершиться после отмены ctx.
+// После Stop новые задачи не запускаются, и Go возвращает false.
+func (g *workerGroup) Go(name string, fn func(ctx context.Context)) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped {
+		log.Printf("Background worker %s not started: shutting down", name)
+		return false
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		fn(g.ctx)
+	}()
+	return true
+}
+
+// Stop отменяет задачи и ждёт их завершения, но не дольше ctx
+func (g *workerGroup) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	g.stopped = true
+	g.mu.Unlock()
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background workers did not stop in time: %w", ctx.Err())
+	}
+}
+
+func publicScheme() string {
+	if cfg.TLS.enabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// publicHost — хост с портом, под которым сервер виден клиентам.
+// Без PublicHost берётся адрес листенера, пустой хост заменяется на localhost.
+func publicHost() string {
+	if cfg.Server.PublicHost != "" {
+		return cfg.Server.PublicHost
+	}
+
+	host, port, err := net.SplitHostPort(cfg.Server.Addr)
+	if err != nil {
+		return cfg.Server.Addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func publicBaseURL() string {
+	return publicScheme() + "://" + publicHost()
+}
+
+func newHTTPServer(handler http.Handler) *http.Server {
+	c := cfg.Server
+	return &http.Server{
+		Addr:              c.Addr,
+		Handler:           handler,
+		ReadTimeout:       time.Duration(c.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(c.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(c.WriteTimeout),
+		IdleTimeout:       time.Duration(c.IdleTimeout),
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}
+}
+
+// serve запускает серверы и блокируется до SIGINT/SIGTERM, после чего
+// перестаёт принимать соединения, дожидается текущих запросов и останавливает
+// фоновые задачи в пределах ShutdownTimeout. Серверы с TLSConfig слушают HTTPS.
+// rpc может быть nil, если gRPC не настроен.
+func serve(rpc *grpcServer, servers ...*http.Server) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, len(servers)+1)
+	if rpc != nil {
+		go func() { errCh <- rpc.listenAndServe() }()
+	}
+	for _, srv := range servers {
+		go func() {
+			if srv.TLSConfig != nil {
+				errCh <- srv.ListenAndServeTLS("", "")
+			} else {
+				errCh <- srv.ListenAndServe()
+			}
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		for _, srv := range servers {
+			srv.Close()
+		}
+		if rpc != nil {
+			rpc.srv.Stop()
+		}
+		background.Stop(context.Background())
+		return err
+	case <-ctx.Done():
+	}
+
+	// Повторный сигнал завершает процесс сразу
+	stop()
+	log.Printf("Shutting down, waiting up to %v for in-flight requests", time.Duration(cfg.Server.ShutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+	defer cancel()
+
+	var err error
+	for _, srv := range servers {
+		err = errors.Join(err, srv.Shutdown(shutdownCtx))
+	}
+	if rpc != nil {
+		err = errors.Join(err, rpc.shutdown(shutdownCtx))
+	}
+	err = errors.Join(err, background.Stop(shutdownCtx))
+	if err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+
+	log.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerGroupStopWaitsForStartedWorkers(t *testing.T) {
+	for range 50 {
+		g := newWorkerGroup()
+		var running atomic.Int32
+
+		var starters sync.WaitGroup
+		for range 8 {
+			starters.Add(1)
+			go func() {
+				defer starters.Done()
+				worker := func(ctx context.Context) {
+					running.Add(1)
+					defer running.Add(-1)
+					<-ctx.Done()
+				}
+				for i := 0; i < 100 && g.Go("test", worker); i++ {
+					time.Sleep(10 * time.Microsecond)
+				}
+			}()
+		}
+
+		time.Sleep(time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := g.Stop(ctx); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+
+		// Задача, принятая Go, не может пережить Stop
+		if n := running.Load(); n != 0 {
+			t.Fatalf("%d workers still running after Stop", n)
+		}
+		starters.Wait()
+		if g.Go("late", func(context.Context) { t.Error("worker started after Stop") }) {
+			t.Fatal("Go accepted a worker after Stop")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SWRConfig — stale-while-revalidate по префиксу пути апстрима. Пока ответу
+// меньше SoftTTL, он отдаётся из кеша; между SoftTTL и HardTTL отдаётся из кеша,
+// а в фоне запрашивается свежий; после HardTTL запрос идёт в апстрим синхронно.
+type SWRConfig struct {
+	Routes map[string]SWRPolicy `json:"routes"`
+	// Warmup — заполнить кеш при старте, чтобы первый запрос тоже был быстрым
+	Warmup bool `json:"warmup"`
+}
+
+type SWRPolicy struct {
+	SoftTTL Duration `json:"softTTL"`
+	HardTTL Duration `json:"hardTTL"`
+}
+
+var (
+	swrRefreshTotal = newCounter("stoloto_swr_refresh_total",
+		"Background cache refreshes by endpoint and result")
+	swrHitsTotal = newCounter("stoloto_swr_hits_total",
+		"Responses served from the stale-while-revalidate cache")
+)
+
+// refreshing — ключи, для которых уже идёт фоновое обновление
+var refreshing sync.Map
+
+// cachedForSWR возвращает ответ из кеша, если для пути включён SWR и запись
+// моложе HardTTL. Устаревшая по SoftTTL запись запускает фоновое обновление.
+func cachedForSWR(req *http.Request, policyPath string) (*http.Response, bool) {
+	policy, ok := longestPrefix(cfg.SWR.Routes, policyPath)
+	if !ok {
+		return nil, false
+	}
+
+	e, ok := upstreamCache.get(cacheKey(req))
+	if !ok {
+		return nil, false
+	}
+
+	age := e.age(time.Now())
+	if age >= time.Duration(policy.HardTTL) {
+		return nil, false
+	}
+	if age >= time.Duration(policy.SoftTTL) {
+		refreshInBackground(req, policyPath)
+	}
+
+	swrHitsTotal.add(labels("endpoint", policyPath), 1)
+	resp := e.response(req)
+	resp.Header.Set("Age", strconv.Itoa(int(age.Seconds())))
+	return resp, true
+}
+
+// refreshInBackground обновляет запись кеша, не дожидаясь результата.
+// Ошибки логируются и попадают в метрики, но клиентам не видны: до HardTTL
+// они продолжают получать предыдущий ответ.
+func refreshInBackground(req *http.Request, policyPath string) {
+	key := cacheKey(req)
+	if _, busy := refreshing.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+
+	started := background.Go("refresh "+key, func(ctx context.Context) {
+		defer refreshing.Delete(key)
+
+		refreshReq := req.Clone(withPriority(ctx, priorityBackground))
+		resp, err := fetchAndStore(refreshReq, policyPath)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+				swrRefreshTotal.add(labels("endpoint", policyPath, "result", "ok"), 1)
+				return
+			}
+			err = &upstreamStatusError{Status: resp.Status, StatusCode: resp.StatusCode}
+		}
+
+		swrRefreshTotal.add(labels("endpoint", policyPath, "result", "error"), 1)
+		log.Printf("Background refresh of %s failed: %v", key, err)
+	})
+	if !started {
+		// Сервер останавливается: обновление не запустится, и ключ
+		// не должен остаться помеченным как обновляемый
+		refreshing.Delete(key)
+	}
+}
+
+// warmupSWR заполняет кеш для эндпоинтов, которые запрашиваются без параметров
+func warmupSWR() {
+	background.Go("swr warmup", func(ctx context.Context) {
+		ctx = withPriority(ctx, priorityBackground)
+
+		if resp, err := handleDrawsHandle(ctx); err != nil {
+			log.Printf("Cache warmup for games list failed: %v", err)
+		} else {
+			resp.Body.Close()
+		}
+
+		if resp, err := handleMomentalHandle(ctx, momentalOptions{}); err != nil {
+			log.Printf("Cache warmup for momental cards failed: %v", err)
+		} else {
+			resp.Body.Close()
+		}
+	})
+}
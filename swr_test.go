@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"DOUPIG/fakestoloto"
+)
+
+func TestSWRServesCachedAndRefreshes(t *testing.T) {
+	fake, proxy := startProxy(t, func(c *Config) {
+		c.SWR.Routes = map[string]SWRPolicy{
+			"/service/games/": {SoftTTL: Duration(time.Hour), HardTTL: Duration(2 * time.Hour)},
+		}
+	})
+	url := proxy.URL + "/api/raw/games"
+
+	if resp := getJSON(t, url, nil); resp.Header.Get("Age") != "" {
+		t.Fatalf("first response has Age %q, want a fresh upstream response", resp.Header.Get("Age"))
+	}
+
+	// Пока запись моложе SoftTTL, ответ отдаётся из кеша без похода в апстрим
+	resp := getJSON(t, url, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Age") == "" {
+		t.Fatalf("second response: status %d, Age %q; want 200 from cache", resp.StatusCode, resp.Header.Get("Age"))
+	}
+	if n := countRequests(fake, "/service/games/"); n != 1 {
+		t.Fatalf("upstream got %d requests after a cached response, want 1", n)
+	}
+
+	// Запись старше SoftTTL: ответ всё равно из кеша, а обновление уходит в фоне.
+	// Ждём, пока оно закончится, чтобы не пересечься со следующим тестом.
+	cfg.SWR.Routes["/service/games/"] = SWRPolicy{SoftTTL: Duration(time.Nanosecond), HardTTL: Duration(2 * time.Hour)}
+	resp = getJSON(t, url, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Age") == "" {
+		t.Fatalf("stale response: status %d, Age %q; want 200 from cache", resp.StatusCode, resp.Header.Get("Age"))
+	}
+
+	key := fakestoloto.APIPrefix + "/service/games/info-new"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, busy := refreshing.Load(key)
+		if !busy && countRequests(fake, "/service/games/") == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("background refresh did not complete: %d upstream requests", countRequests(fake, "/service/games/"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSWRRefreshNotStartedDuringShutdown(t *testing.T) {
+	prev := background
+	t.Cleanup(func() { background = prev })
+
+	background = newWorkerGroup()
+	if err := background.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://upstream.test/service/games/info-new", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshInBackground(req, "/service/games/info-new")
+
+	if _, busy := refreshing.Load(cacheKey(req)); busy {
+		t.Fatal("refresh key left in refreshing after the worker was refused")
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSConfig — HTTPS и проверка клиентских сертификатов (mTLS).
+// Пустой CertFile оставляет сервер на обычном HTTP.
+type TLSConfig struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+	// ClientCAFile включает проверку клиентских сертификатов по указанному CA
+	ClientCAFile string `json:"clientCAFile"`
+	// ClientAuth: "verify-if-given" (по умолчанию при заданном CA) или "require"
+	ClientAuth string `json:"clientAuth"`
+	// RedirectAddr — адрес HTTP-листенера, перенаправляющего на HTTPS, например ":80"
+	RedirectAddr string `json:"redirectAddr"`
+	// ReloadInterval — как часто проверять, не изменились ли файлы на диске
+	ReloadInterval Duration `json:"reloadInterval"`
+}
+
+func (c TLSConfig) enabled() bool {
+	return c.CertFile != ""
+}
+
+// certReloader держит текущие сертификат и CA и перечитывает их,
+// когда файлы на диске меняются (например, после обновления certbot)
+type certReloader struct {
+	conf TLSConfig
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	checked   time.Time
+}
+
+func newCertReloader(c TLSConfig) (*certReloader, error) {
+	r := &certReloader{conf: c, modTimes: make(map[string]time.Time)}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.conf.CertFile, r.conf.KeyFile}
+	if r.conf.ClientCAFile != "" {
+		files = append(files, r.conf.ClientCAFile)
+	}
+	return files
+}
+
+// load читает файлы заново. Вызывается под mu или до начала работы сервера.
+func (r *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("error loading TLS certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.conf.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.conf.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("error reading client CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %s", r.conf.ClientCAFile)
+		}
+	}
+
+	for _, f := range r.files() {
+		if info, err := os.Stat(f); err == nil {
+			r.modTimes[f] = info.ModTime()
+		}
+	}
+	r.cert, r.clientCAs = &cert, pool
+	return nil
+}
+
+// maybeReload перечитывает файлы не чаще ReloadInterval и только если они изменились.
+// Ошибка перезагрузки не прерывает работу: остаётся предыдущий сертификат.
+func (r *certReloader) maybeReload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checked) < time.Duration(r.conf.ReloadInterval) {
+		return
+	}
+	r.checked = time.Now()
+
+	changed := false
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err == nil && !info.ModTime().Equal(r.modTimes[f]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		log.Printf("TLS reload failed, keeping previous certificate: %v", err)
+		return
+	}
+	log.Println("TLS certificate reloaded")
+}
+
+// tlsConfig собирает конфигурацию, в которой сертификат и CA берутся
+// из reloader на каждое новое соединение
+func (r *certReloader) tlsConfig() *tls.Config {
+	clientAuth := tls.NoClientCert
+	if r.conf.ClientCAFile != "" {
+		clientAuth = tls.VerifyClientCertIfGiven
+		if r.conf.ClientAuth == "require" {
+			clientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.maybeReload()
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			cert := r.cert
+			return &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				NextProtos: []string{"h2", "http/1.1"},
+				GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+					return cert, nil
+				},
+				ClientAuth: clientAuth,
+				ClientCAs:  r.clientCAs,
+			}, nil
+		},
+	}
+}
+
+// newRedirectServer перенаправляет HTTP-запросы на HTTPS-адрес основного сервера
+func newRedirectServer(addr, httpsAddr string) *http.Server {
+	_, httpsPort, _ := net.SplitHostPort(httpsAddr)
+
+	return &http.Server{
+		Addr:              addr,
+		ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout),
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := r.Host
+			if h, _, err := net.SplitHostPort(r.Host); err == nil {
+				host = h
+			}
+			if httpsPort != "" && httpsPort != "443" {
+				host = net.JoinHostPort(host, httpsPort)
+			}
+			http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+		}),
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert — сертификат с ключом для тестов, подписанный parent (или самоподписанный)
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, serial int64, parent *testCert, tmpl x509.Certificate) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.SerialNumber = big.NewInt(serial)
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := &tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func newTestCA(t *testing.T, serial int64) *testCert {
+	return newTestCert(t, serial, nil, x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+}
+
+func newServerCert(t *testing.T, serial int64, ca *testCert) *testCert {
+	return newTestCert(t, serial, ca, x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+func newClientCert(t *testing.T, serial int64, ca *testCert) *testCert {
+	return newTestCert(t, serial, ca, x509.Certificate{
+		Subject:     pkix.Name{CommonName: "partner"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+}
+
+// writePEM пишет сертификат и ключ в файлы certFile и keyFile
+func (c *testCert) writePEM(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile == "" {
+		return
+	}
+	key, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func (c *testCert) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(c.cert)
+	return pool
+}
+
+// serveTLS запускает HTTPS-сервер с конфигурацией из reloader
+func serveTLS(t *testing.T, r *certReloader) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), TLSConfig: r.tlsConfig()}
+	go srv.ServeTLS(lis, "", "")
+	t.Cleanup(func() { srv.Close() })
+	return lis.Addr().String()
+}
+
+// tlsGet выполняет GET по новому соединению и возвращает сертификат сервера
+func tlsGet(addr string, roots *x509.CertPool, client *tls.Certificate) (*x509.Certificate, error) {
+	conf := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if client != nil {
+		conf.Certificates = []tls.Certificate{*client}
+	}
+	c := &http.Client{Transport: &http.Transport{TLSClientConfig: conf, DisableKeepAlives: true}, Timeout: 5 * time.Second}
+
+	resp, err := c.Get("https://" + addr + "/")
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp.TLS.PeerCertificates[0], nil
+}
+
+func TestTLSReloadsCertificate(t *testing.T) {
+	dir := t.TempDir()
+	conf := TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	ca := newTestCA(t, 1)
+	newServerCert(t, 10, ca).writePEM(t, conf.CertFile, conf.KeyFile)
+
+	r, err := newCertReloader(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serveTLS(t, r)
+
+	cert, err := tlsGet(addr, ca.pool(), nil)
+	if err != nil || cert.SerialNumber.Int64() != 10 {
+		t.Fatalf("first certificate: %v, %v", cert, err)
+	}
+
+	// Новый сертификат на диске подхватывается следующим соединением
+	newServerCert(t, 11, ca).writePEM(t, conf.CertFile, conf.KeyFile)
+	later := time.Now().Add(time.Minute)
+	for _, f := range []string{conf.CertFile, conf.KeyFile} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cert, err := tlsGet(addr, ca.pool(), nil); err != nil || cert.SerialNumber.Int64() != 11 {
+		t.Fatalf("after reload: %v, %v", cert, err)
+	}
+
+	// Битый файл не ломает сервер: остаётся предыдущий сертификат
+	if err := os.WriteFile(conf.KeyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cert, err := tlsGet(addr, ca.pool(), nil); err != nil || cert.SerialNumber.Int64() != 11 {
+		t.Fatalf("after failed reload: %v, %v", cert, err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, other := newTestCA(t, 1), newTestCA(t, 2)
+	base := TLSConfig{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	newServerCert(t, 10, ca).writePEM(t, base.CertFile, base.KeyFile)
+	ca.writePEM(t, base.ClientCAFile, "")
+
+	partner := newClientCert(t, 20, ca).tlsCertificate()
+	stranger := newClientCert(t, 21, other).tlsCertificate()
+
+	for _, tc := range []struct {
+		clientAuth string
+		name       string
+		client     *tls.Certificate
+		ok         bool
+	}{
+		{"", "no certificate", nil, true},
+		{"", "partner", &partner, true},
+		{"", "other CA", &stranger, false},
+		{"require", "no certificate", nil, false},
+		{"require", "partner", &partner, true},
+		{"require", "other CA", &stranger, false},
+	} {
+		conf := base
+		conf.ClientAuth = tc.clientAuth
+		r, err := newCertReloader(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = tlsGet(serveTLS(t, r), ca.pool(), tc.client)
+		if tc.ok != (err == nil) {
+			t.Errorf("clientAuth %q, %s: err = %v, want ok %v", tc.clientAuth, tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	conf := TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	if _, err := newCertReloader(conf); err == nil {
+		t.Fatal("missing certificate files were accepted")
+	}
+
+	newServerCert(t, 10, newTestCA(t, 1)).writePEM(t, conf.CertFile, conf.KeyFile)
+	conf.ClientCAFile = filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(conf.ClientCAFile, []byte("no certificates here"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newCertReloader(conf); err == nil {
+		t.Fatal("client CA file without certificates was accepted")
+	}
+}
+
+func TestRedirectServer(t *testing.T) {
+	for _, tc := range []struct {
+		httpsAddr, host, want string
+	}{
+		{":443", "example.ru", "https://example.ru/api/v1/games?sort=name"},
+		{":443", "example.ru:80", "https://example.ru/api/v1/games?sort=name"},
+		{":8443", "example.ru:8080", "https://example.ru:8443/api/v1/games?sort=name"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/games?sort=name", nil)
+		r.Host = tc.host
+		rec := httptest.NewRecorder()
+		newRedirectServer(":80", tc.httpsAddr).Handler.ServeHTTP(rec, r)
+
+		if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != tc.want {
+			t.Errorf("%s via %s: %d %s, want 301 %s", tc.host, tc.httpsAddr, rec.Code, rec.Header().Get("Location"), tc.want)
+		}
+	}
+}